@@ -0,0 +1,218 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/auth"
+	"github.com/breathbath/healthReadyChecks/logging"
+	"github.com/breathbath/healthReadyChecks/ready"
+)
+
+// forceRequest body of /health/force and /ready/force, Healthy is for health and Ready is for readiness
+type forceRequest struct {
+	Healthy *bool  `json:"healthy"`
+	Ready   *bool  `json:"ready"`
+	Reason  string `json:"reason"`
+	// TTL e.g. "15m", empty means until the override is cleared
+	TTL string `json:"ttl"`
+}
+
+// muteRequest body of /ready/mute and /ready/unmute
+type muteRequest struct {
+	Test   string `json:"test"`
+	Reason string `json:"reason"`
+	TTL    string `json:"ttl"`
+}
+
+// badRequestError is responded with 400
+type badRequestError struct {
+	reason string
+}
+
+func (bre badRequestError) Error() string {
+	return bre.reason
+}
+
+// NewHandler gives http.Handler of admin operations on the overrides, routes are matched by the end of the path,
+// so the handler can be mounted under any prefix, e.g. with rest.WithHandler(srv, "/admin/", handler):
+//
+//	GET  /overrides      all active overrides
+//	POST /health/force   {"healthy": false, "reason": "disk migration", "ttl": "15m"}
+//	POST /health/clear
+//	POST /health/reset   resets errors of the errors listener
+//	POST /ready/force    {"ready": false, "reason": "draining", "ttl": "15m"}
+//	POST /ready/clear
+//	POST /ready/mute     {"test": "kafka", "reason": "known flaky", "ttl": "1h"}
+//	POST /ready/unmute   {"test": "kafka"}
+//
+// successful operations respond with all active overrides as json, callers not allowed by the authenticator get 401,
+// a nil authenticator denies all callers
+func NewHandler(o *Overrides, authenticator auth.Authenticator) http.Handler {
+	if authenticator == nil {
+		authenticator = func(c auth.Caller) bool {
+			return false
+		}
+	}
+
+	routes := map[string]func(r *http.Request) error{
+		"/health/force": func(r *http.Request) error {
+			fr, ttl, err := readForceRequest(r)
+			if err != nil {
+				return err
+			}
+			if fr.Healthy == nil {
+				return badRequestError{reason: "healthy is required"}
+			}
+			o.ForceHealth(*fr.Healthy, fr.Reason, ttl)
+
+			return nil
+		},
+		"/health/clear": func(r *http.Request) error {
+			o.ClearHealth()
+			return nil
+		},
+		"/health/reset": func(r *http.Request) error {
+			return o.ResetErrors()
+		},
+		"/ready/force": func(r *http.Request) error {
+			fr, ttl, err := readForceRequest(r)
+			if err != nil {
+				return err
+			}
+			if fr.Ready == nil {
+				return badRequestError{reason: "ready is required"}
+			}
+			o.ForceReady(*fr.Ready, fr.Reason, ttl)
+
+			return nil
+		},
+		"/ready/clear": func(r *http.Request) error {
+			o.ClearReady()
+			return nil
+		},
+		"/ready/mute": func(r *http.Request) error {
+			mr, ttl, err := readMuteRequest(r)
+			if err != nil {
+				return err
+			}
+			if mr.Reason == "" {
+				return badRequestError{reason: "reason is required"}
+			}
+
+			return o.Mute(mr.Test, mr.Reason, ttl)
+		},
+		"/ready/unmute": func(r *http.Request) error {
+			mr, _, err := readMuteRequest(r)
+			if err != nil {
+				return err
+			}
+
+			return o.Unmute(mr.Test)
+		},
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/overrides") {
+			if r.Method != http.MethodGet {
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+				return
+			}
+			writeState(w, o.State())
+			return
+		}
+
+		for suffix, operation := range routes {
+			if !strings.HasSuffix(r.URL.Path, suffix) {
+				continue
+			}
+			if r.Method != http.MethodPost {
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+				return
+			}
+
+			err := operation(r)
+			if err != nil {
+				http.Error(w, err.Error(), errorStatusCode(err))
+				return
+			}
+
+			logging.L.InfoF("Admin operation %s is done", suffix)
+			writeState(w, o.State())
+			return
+		}
+
+		http.NotFound(w, r)
+	})
+
+	return auth.NewRequireMiddleware(authenticator)(handler)
+}
+
+func readForceRequest(r *http.Request) (forceRequest, time.Duration, error) {
+	fr := forceRequest{}
+	err := json.NewDecoder(r.Body).Decode(&fr)
+	if err != nil {
+		return fr, 0, badRequestError{reason: fmt.Sprintf("invalid body: %v", err)}
+	}
+	if fr.Reason == "" {
+		return fr, 0, badRequestError{reason: "reason is required"}
+	}
+
+	ttl, err := parseTTL(fr.TTL)
+
+	return fr, ttl, err
+}
+
+func readMuteRequest(r *http.Request) (muteRequest, time.Duration, error) {
+	mr := muteRequest{}
+	err := json.NewDecoder(r.Body).Decode(&mr)
+	if err != nil {
+		return mr, 0, badRequestError{reason: fmt.Sprintf("invalid body: %v", err)}
+	}
+	if mr.Test == "" {
+		return mr, 0, badRequestError{reason: "test is required"}
+	}
+
+	ttl, err := parseTTL(mr.TTL)
+
+	return mr, ttl, err
+}
+
+func parseTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d < 0 {
+		return 0, badRequestError{reason: fmt.Sprintf("invalid ttl %q", ttl)}
+	}
+
+	return d, nil
+}
+
+func errorStatusCode(err error) int {
+	switch {
+	case errors.As(err, &badRequestError{}):
+		return http.StatusBadRequest
+	case errors.Is(err, ready.ErrUnknownTest):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotConfigured):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeState(w http.ResponseWriter, st State) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(st)
+	if err != nil {
+		logging.L.ErrorF("Failed to write body: %v", err)
+	}
+}
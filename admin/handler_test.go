@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/breathbath/healthReadyChecks/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	o := NewOverrides(WithTestChecker(newTestChecker()))
+	handler := NewHandler(o, auth.BearerToken("secret"))
+
+	testCases := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		token              string
+		expectedStatusCode int
+		expectedBody       string
+		assertState        func(t *testing.T, st State)
+	}{
+		{
+			name:               "no token",
+			method:             http.MethodGet,
+			path:               "/admin/overrides",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "Unauthorized\n",
+		},
+		{
+			name:               "force not ready",
+			method:             http.MethodPost,
+			path:               "/admin/ready/force",
+			body:               `{"ready": false, "reason": "draining", "ttl": "15m"}`,
+			token:              "secret",
+			expectedStatusCode: http.StatusOK,
+			assertState: func(t *testing.T, st State) {
+				assert.NotNil(t, st.Ready)
+				assert.False(t, st.Ready.IsUp)
+				assert.Equal(t, "draining", st.Ready.Reason)
+				assert.NotNil(t, st.Ready.Until)
+			},
+		},
+		{
+			name:               "force healthy",
+			method:             http.MethodPost,
+			path:               "/admin/health/force",
+			body:               `{"healthy": true, "reason": "false alarm"}`,
+			token:              "secret",
+			expectedStatusCode: http.StatusOK,
+			assertState: func(t *testing.T, st State) {
+				assert.NotNil(t, st.Health)
+				assert.True(t, st.Health.IsUp)
+				assert.Nil(t, st.Health.Until)
+				assert.NotNil(t, st.Ready)
+			},
+		},
+		{
+			name:               "mute",
+			method:             http.MethodPost,
+			path:               "/admin/ready/mute",
+			body:               `{"test": "kafka", "reason": "known outage"}`,
+			token:              "secret",
+			expectedStatusCode: http.StatusOK,
+			assertState: func(t *testing.T, st State) {
+				assert.Len(t, st.Muted, 1)
+			},
+		},
+		{
+			name:               "list",
+			method:             http.MethodGet,
+			path:               "/admin/overrides",
+			token:              "secret",
+			expectedStatusCode: http.StatusOK,
+			assertState: func(t *testing.T, st State) {
+				assert.NotNil(t, st.Health)
+				assert.NotNil(t, st.Ready)
+				assert.Len(t, st.Muted, 1)
+			},
+		},
+		{
+			name:               "clear ready",
+			method:             http.MethodPost,
+			path:               "/admin/ready/clear",
+			token:              "secret",
+			expectedStatusCode: http.StatusOK,
+			assertState: func(t *testing.T, st State) {
+				assert.Nil(t, st.Ready)
+			},
+		},
+		{
+			name:               "mute unknown test",
+			method:             http.MethodPost,
+			path:               "/admin/ready/mute",
+			body:               `{"test": "redis", "reason": "known outage"}`,
+			token:              "secret",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "unknown ready test redis\n",
+		},
+		{
+			name:               "missing reason",
+			method:             http.MethodPost,
+			path:               "/admin/health/force",
+			body:               `{"healthy": false}`,
+			token:              "secret",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "reason is required\n",
+		},
+		{
+			name:               "missing state",
+			method:             http.MethodPost,
+			path:               "/admin/ready/force",
+			body:               `{"reason": "draining"}`,
+			token:              "secret",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "ready is required\n",
+		},
+		{
+			name:               "invalid ttl",
+			method:             http.MethodPost,
+			path:               "/admin/ready/force",
+			body:               `{"ready": false, "reason": "draining", "ttl": "soon"}`,
+			token:              "secret",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "invalid ttl \"soon\"\n",
+		},
+		{
+			name:               "reset without listener",
+			method:             http.MethodPost,
+			path:               "/admin/health/reset",
+			token:              "secret",
+			expectedStatusCode: http.StatusNotImplemented,
+			expectedBody:       "errors listener is not configured\n",
+		},
+		{
+			name:               "wrong method",
+			method:             http.MethodGet,
+			path:               "/admin/health/clear",
+			token:              "secret",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedBody:       "Method Not Allowed\n",
+		},
+		{
+			name:               "unknown operation",
+			method:             http.MethodPost,
+			path:               "/admin/restart",
+			token:              "secret",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "404 page not found\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req := httptest.NewRequest(testCase.method, testCase.path, strings.NewReader(testCase.body))
+			if testCase.token != "" {
+				req.Header.Set("Authorization", "Bearer "+testCase.token)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, rec.Code)
+			if testCase.assertState == nil {
+				assert.Equal(t, testCase.expectedBody, rec.Body.String())
+				return
+			}
+
+			st := State{}
+			err := json.Unmarshal(rec.Body.Bytes(), &st)
+			assert.NoError(t, err)
+			testCase.assertState(t, st)
+		})
+	}
+}
+
+func TestHandlerWithoutAuthenticator(t *testing.T) {
+	handler := NewHandler(NewOverrides(), nil)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/overrides", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,386 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/breathbath/healthReadyChecks/logging"
+	"github.com/breathbath/healthReadyChecks/ready"
+)
+
+// OverrideCheckName name of the check which replaces all checks of health.ComponentsProvider and ready reports while an override is active
+const OverrideCheckName = ready.OverrideTestName
+
+// ErrNotConfigured is given for operations on parts which were not given to NewOverrides, e.g. Mute without WithTestChecker
+var ErrNotConfigured = errors.New("not configured")
+
+// Override manually forced state of health or readiness
+type Override struct {
+	IsUp bool `json:"up"`
+	// State e.g. "not ready" or "healthy"
+	State  string    `json:"state"`
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+	// Until the override is active, nil means until it's cleared
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// String describes the override, e.g. "forced not ready: deployment of db, until 2026-10-18T18:00:00Z"
+func (o Override) String() string {
+	desc := fmt.Sprintf("forced %s: %s", o.State, o.Reason)
+	if o.Until != nil {
+		desc += ", until " + o.Until.UTC().Format(time.RFC3339)
+	}
+
+	return desc
+}
+
+func (o *Override) isActive(now time.Time) bool {
+	return o != nil && (o.Until == nil || now.Before(*o.Until))
+}
+
+// Muter mutes ready tests, e.g. ready.TestChecker
+type Muter interface {
+	Mute(name, reason string, until time.Time) error
+	Unmute(name string)
+	Muted() []ready.Mute
+}
+
+// Resetter forgets errors of the current window, e.g. health.ErrsListener
+type Resetter interface {
+	Reset()
+}
+
+// State all active overrides
+type State struct {
+	Health *Override    `json:"health,omitempty"`
+	Ready  *Override    `json:"ready,omitempty"`
+	Muted  []ready.Mute `json:"muted"`
+}
+
+// Option configures Overrides at construction
+type Option func(o *Overrides)
+
+// WithErrsListener lets Overrides reset errors of the listener, e.g. of health.ErrsListener
+func WithErrsListener(resetter Resetter) Option {
+	return func(o *Overrides) {
+		o.resetter = resetter
+	}
+}
+
+// WithTestChecker lets Overrides mute tests of the checker, e.g. of ready.TestChecker
+func WithTestChecker(muter Muter) Option {
+	return func(o *Overrides) {
+		o.muter = muter
+	}
+}
+
+// Overrides keeps manual overrides of health and readiness, e.g. to pull a pod out of rotation during an incident without killing it,
+// overrides apply to checkers wrapped with HealthChecker and ReadyChecker
+type Overrides struct {
+	lock      sync.Mutex
+	health    *Override
+	ready     *Override
+	resetter  Resetter
+	muter     Muter
+	subsrFunc func(reason string)
+}
+
+// NewOverrides constructor for Overrides
+func NewOverrides(opts ...Option) *Overrides {
+	o := &Overrides{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+func newOverride(isUp bool, state, reason string, ttl time.Duration) *Override {
+	now := time.Now().UTC()
+	ov := &Override{IsUp: isUp, State: state, Reason: reason, Since: now}
+	if ttl > 0 {
+		until := now.Add(ttl)
+		ov.Until = &until
+	}
+
+	return ov
+}
+
+// ForceHealth reports the health as given until the ttl is over, 0 ttl means until ClearHealth
+func (o *Overrides) ForceHealth(isHealthy bool, reason string, ttl time.Duration) {
+	state := "unhealthy"
+	if isHealthy {
+		state = "healthy"
+	}
+	ov := newOverride(isHealthy, state, reason, ttl)
+	logging.L.WarnF("Health is %s", ov)
+
+	o.lock.Lock()
+	wasForcedUnhealthy := o.health.isActive(time.Now()) && !o.health.IsUp
+	o.health = ov
+	subsrFunc := o.subsrFunc
+	o.lock.Unlock()
+
+	if isHealthy || wasForcedUnhealthy || subsrFunc == nil {
+		return
+	}
+	subsrFunc(ov.String())
+}
+
+// ClearHealth removes the health override
+func (o *Overrides) ClearHealth() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	logging.L.InfoF("Health override is cleared")
+	o.health = nil
+}
+
+// ForceReady reports the readiness as given until the ttl is over, 0 ttl means until ClearReady
+func (o *Overrides) ForceReady(isReady bool, reason string, ttl time.Duration) {
+	state := "not ready"
+	if isReady {
+		state = "ready"
+	}
+	ov := newOverride(isReady, state, reason, ttl)
+	logging.L.WarnF("Readiness is %s", ov)
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.ready = ov
+}
+
+// ClearReady removes the readiness override
+func (o *Overrides) ClearReady() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	logging.L.InfoF("Ready override is cleared")
+	o.ready = nil
+}
+
+// ResetErrors resets errors of the listener given with WithErrsListener
+func (o *Overrides) ResetErrors() error {
+	if o.resetter == nil {
+		return fmt.Errorf("errors listener is %w", ErrNotConfigured)
+	}
+
+	o.resetter.Reset()
+
+	return nil
+}
+
+// Mute mutes the test of the checker given with WithTestChecker until the ttl is over, 0 ttl means until Unmute
+func (o *Overrides) Mute(name, reason string, ttl time.Duration) error {
+	if o.muter == nil {
+		return fmt.Errorf("test checker is %w", ErrNotConfigured)
+	}
+
+	until := time.Time{}
+	if ttl > 0 {
+		until = time.Now().Add(ttl)
+	}
+
+	return o.muter.Mute(name, reason, until)
+}
+
+// Unmute unmutes the test of the checker given with WithTestChecker
+func (o *Overrides) Unmute(name string) error {
+	if o.muter == nil {
+		return fmt.Errorf("test checker is %w", ErrNotConfigured)
+	}
+
+	o.muter.Unmute(name)
+
+	return nil
+}
+
+// State gives all active overrides
+func (o *Overrides) State() State {
+	st := State{Health: o.activeHealth(), Ready: o.activeReady(), Muted: []ready.Mute{}}
+	if o.muter != nil {
+		st.Muted = o.muter.Muted()
+	}
+
+	return st
+}
+
+func (o *Overrides) activeHealth() *Override {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if !o.health.isActive(time.Now()) {
+		o.health = nil
+		return nil
+	}
+	ov := *o.health
+
+	return &ov
+}
+
+func (o *Overrides) activeReady() *Override {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if !o.ready.isActive(time.Now()) {
+		o.ready = nil
+		return nil
+	}
+	ov := *o.ready
+
+	return &ov
+}
+
+func (o *Overrides) subscribe(sf func(reason string)) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.subsrFunc = sf
+}
+
+// HealthChecker wraps the checker, so it reports the health override while it's active,
+// the wrapper implements health.ComponentsProvider and health.StatusProvider if the checker does
+func (o *Overrides) HealthChecker(checker health.Checker) health.Checker {
+	ho := healthOverride{overrides: o, checker: checker}
+	if sp, ok := checker.(health.StatusProvider); ok {
+		return statusHealthOverride{healthOverride: ho, provider: sp}
+	}
+
+	return ho
+}
+
+// ReadyChecker wraps the checker, so it reports the readiness override while it's active,
+// the wrapper implements ready.SelectiveChecker if the checker does
+func (o *Overrides) ReadyChecker(checker ready.Checker) ready.Checker {
+	ro := readyOverride{overrides: o, checker: checker}
+	if sc, ok := checker.(ready.SelectiveChecker); ok {
+		return selectiveReadyOverride{readyOverride: ro, selective: sc}
+	}
+
+	return ro
+}
+
+type healthOverride struct {
+	overrides *Overrides
+	checker   health.Checker
+}
+
+// IsHealthy health.Checker implementation
+func (ho healthOverride) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	ov := ho.overrides.activeHealth()
+	if ov == nil {
+		return ho.checker.IsHealthy()
+	}
+	if ov.IsUp {
+		return true, ""
+	}
+
+	return false, ov.String()
+}
+
+// SubscribeToUnhealthyChange subscribes the callback to the checker and to forced unhealthy overrides,
+// like with other checkers the callback replaces the previous one
+func (ho healthOverride) SubscribeToUnhealthyChange(sf func(reason string)) {
+	ho.checker.SubscribeToUnhealthyChange(sf)
+	ho.overrides.subscribe(sf)
+}
+
+// Components health.ComponentsProvider implementation, an active override replaces all components
+func (ho healthOverride) Components() []health.Component {
+	if ov := ho.overrides.activeHealth(); ov != nil {
+		return []health.Component{{Name: OverrideCheckName, Checker: overrideChecker{override: *ov}}}
+	}
+	if cp, ok := ho.checker.(health.ComponentsProvider); ok {
+		return cp.Components()
+	}
+
+	return []health.Component{{Name: "health", Checker: ho.checker}}
+}
+
+type statusHealthOverride struct {
+	healthOverride
+	provider health.StatusProvider
+}
+
+// Status health.StatusProvider implementation, an active override replaces the state and the reason
+func (sho statusHealthOverride) Status() health.Status {
+	st := sho.provider.Status()
+	ov := sho.overrides.activeHealth()
+	if ov == nil {
+		return st
+	}
+
+	st.Override = ov.String()
+	st.Since = ov.Since
+	if ov.IsUp {
+		st.State = health.StateHealthy
+		st.Reason = ""
+	} else {
+		st.State = health.StateUnhealthy
+		st.Reason = ov.String()
+	}
+
+	return st
+}
+
+// overrideChecker reports the override as a health component
+type overrideChecker struct {
+	override Override
+}
+
+// IsHealthy health.Checker implementation
+func (oc overrideChecker) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	if oc.override.IsUp {
+		return true, ""
+	}
+
+	return false, oc.override.String()
+}
+
+// SubscribeToUnhealthyChange health.Checker implementation, the override doesn't change
+func (oc overrideChecker) SubscribeToUnhealthyChange(sf func(reason string)) {}
+
+type readyOverride struct {
+	overrides *Overrides
+	checker   ready.Checker
+}
+
+// IsReady ready.Checker implementation, tests are not evaluated while the override is active
+func (ro readyOverride) IsReady(ctx context.Context) (isReady bool, err error) {
+	ov := ro.overrides.activeReady()
+	if ov == nil {
+		return ro.checker.IsReady(ctx)
+	}
+	if ov.IsUp {
+		return true, nil
+	}
+
+	return false, errors.New(ov.String())
+}
+
+type selectiveReadyOverride struct {
+	readyOverride
+	selective ready.SelectiveChecker
+}
+
+// ReportFor ready.SelectiveChecker implementation, like IsReady tests are not evaluated while the override is active,
+// the report has a single OverrideCheckName test then
+func (sro selectiveReadyOverride) ReportFor(ctx context.Context, sel ready.Selection) (ready.Report, error) {
+	ov := sro.overrides.activeReady()
+	if ov == nil {
+		return sro.selective.ReportFor(ctx, sel)
+	}
+
+	tr := ready.TestReport{Name: OverrideCheckName, IsReady: ov.IsUp}
+	if !ov.IsUp {
+		tr.Error = ov.String()
+	}
+
+	return ready.Report{IsReady: ov.IsUp, Tests: []ready.TestReport{tr}, Override: ov.String()}, nil
+}
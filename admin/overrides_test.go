@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/breathbath/healthReadyChecks/ready"
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestChecker() ready.TestChecker {
+	return ready.NewTestChecker([]ready.Test{
+		{TestFunc: func() error { return nil }, Name: "db"},
+		{TestFunc: func() error { return errors.New("broker is down") }, Name: "kafka"},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+}
+
+func TestForceHealth(t *testing.T) {
+	lis := health.NewErrsListener(1, time.Minute, errs.NewErrStream(0))
+	o := NewOverrides()
+	checker := o.HealthChecker(lis)
+
+	reasons := []string{}
+	checker.SubscribeToUnhealthyChange(func(reason string) {
+		reasons = append(reasons, reason)
+	})
+
+	o.ForceHealth(false, "disk migration", 0)
+
+	isHealthy, reason := checker.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Equal(t, "forced unhealthy: disk migration", reason)
+	assert.Equal(t, []string{"forced unhealthy: disk migration"}, reasons)
+
+	st := checker.(health.StatusProvider).Status()
+	assert.Equal(t, health.StateUnhealthy, st.State)
+	assert.Equal(t, "forced unhealthy: disk migration", st.Override)
+
+	components := checker.(health.ComponentsProvider).Components()
+	assert.Len(t, components, 1)
+	assert.Equal(t, OverrideCheckName, components[0].Name)
+
+	o.ClearHealth()
+
+	isHealthy, reason = checker.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", reason)
+	assert.Equal(t, "", checker.(health.StatusProvider).Status().Override)
+	assert.Equal(t, "health", checker.(health.ComponentsProvider).Components()[0].Name)
+}
+
+func TestForceHealthReplacesSubscriber(t *testing.T) {
+	lis := health.NewErrsListener(1, time.Minute, errs.NewErrStream(0))
+	o := NewOverrides()
+	checker := o.HealthChecker(lis)
+
+	first, second := []string{}, []string{}
+	checker.SubscribeToUnhealthyChange(func(reason string) {
+		first = append(first, reason)
+	})
+	checker.SubscribeToUnhealthyChange(func(reason string) {
+		second = append(second, reason)
+	})
+
+	o.ForceHealth(false, "disk migration", 0)
+
+	assert.Equal(t, []string{}, first)
+	assert.Equal(t, []string{"forced unhealthy: disk migration"}, second)
+}
+
+func TestForceHealthExpiry(t *testing.T) {
+	lis := health.NewErrsListener(1, time.Minute, errs.NewErrStream(0))
+	o := NewOverrides()
+	checker := o.HealthChecker(lis)
+
+	o.ForceHealth(false, "disk migration", time.Millisecond*20)
+
+	isHealthy, _ := checker.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.NotNil(t, o.State().Health.Until)
+
+	time.Sleep(time.Millisecond * 30)
+
+	isHealthy, _ = checker.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Nil(t, o.State().Health)
+}
+
+func TestForceReady(t *testing.T) {
+	var calls int32
+	o := NewOverrides()
+	checker := o.ReadyChecker(ready.NewTestChecker([]ready.Test{
+		{TestFunc: func() error { atomic.AddInt32(&calls, 1); return nil }, Name: "db"},
+		{TestFunc: func() error { atomic.AddInt32(&calls, 1); return errors.New("broker is down") }, Name: "kafka"},
+	}, 1, time.Millisecond, sleep.NewSleeperMock()))
+
+	isReady, err := checker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.Error(t, err)
+
+	o.ForceReady(true, "kafka is flaky", 0)
+
+	isReady, err = checker.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+
+	report, err := checker.(ready.SelectiveChecker).ReportFor(context.Background(), ready.Selection{})
+	assert.NoError(t, err)
+	assert.True(t, report.IsReady)
+	assert.Equal(t, "forced ready: kafka is flaky", report.Override)
+	assert.Equal(t, []ready.TestReport{{Name: OverrideCheckName, IsReady: true}}, report.Tests)
+
+	o.ForceReady(false, "draining", 0)
+
+	isReady, err = checker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "forced not ready: draining")
+
+	report, err = checker.(ready.SelectiveChecker).ReportFor(context.Background(), ready.Selection{Exclude: []string{"kafka"}})
+	assert.NoError(t, err)
+	assert.False(t, report.IsReady)
+	assert.Equal(t, []ready.TestReport{{Name: OverrideCheckName, Error: "forced not ready: draining"}}, report.Tests)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "tests should not run while the override is active")
+
+	o.ClearReady()
+
+	report, err = checker.(ready.SelectiveChecker).ReportFor(context.Background(), ready.Selection{Include: []string{"db"}})
+	assert.NoError(t, err)
+	assert.True(t, report.IsReady)
+	assert.Equal(t, "", report.Override)
+}
+
+func TestMuteAndReset(t *testing.T) {
+	tc := newTestChecker()
+	lis := health.NewErrsListener(1, time.Minute, errs.NewErrStream(0))
+	o := NewOverrides(WithTestChecker(tc), WithErrsListener(lis))
+
+	err := o.Mute("kafka", "known outage", time.Hour)
+	assert.NoError(t, err)
+
+	isReady, err := o.ReadyChecker(tc).IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+
+	st := o.State()
+	assert.Len(t, st.Muted, 1)
+	assert.Equal(t, "kafka", st.Muted[0].Name)
+	assert.NotNil(t, st.Muted[0].Until)
+
+	err = o.Mute("redis", "known outage", 0)
+	assert.True(t, errors.Is(err, ready.ErrUnknownTest))
+
+	assert.NoError(t, o.Unmute("kafka"))
+	assert.Equal(t, []ready.Mute{}, o.State().Muted)
+
+	assert.NoError(t, o.ResetErrors())
+}
+
+func TestNotConfigured(t *testing.T) {
+	o := NewOverrides()
+
+	assert.True(t, errors.Is(o.ResetErrors(), ErrNotConfigured))
+	assert.True(t, errors.Is(o.Mute("db", "maintenance", 0), ErrNotConfigured))
+	assert.True(t, errors.Is(o.Unmute("db"), ErrNotConfigured))
+	assert.Equal(t, State{Muted: []ready.Mute{}}, o.State())
+}
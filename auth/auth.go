@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// AuthorizationKey name of the http header and the grpc metadata key with credentials of the caller
+const AuthorizationKey = "authorization"
+
+// Caller credentials and origin of a http request or a grpc call
+type Caller struct {
+	// Authorization value of the Authorization header or metadata, e.g. "Bearer token"
+	Authorization string
+	// RemoteIP address of the peer, proxy headers like X-Forwarded-For are not trusted
+	RemoteIP net.IP
+	// ClientCerts leaf certificates of verified client certificate chains
+	ClientCerts []*x509.Certificate
+}
+
+// Authenticator tells if the caller is allowed to see details, e.g. failure reasons of checks
+type Authenticator func(c Caller) bool
+
+// CallerFromRequest gives the caller of the http request
+func CallerFromRequest(r *http.Request) Caller {
+	c := Caller{
+		Authorization: r.Header.Get(AuthorizationKey),
+		RemoteIP:      parseIP(r.RemoteAddr),
+	}
+	if r.TLS != nil {
+		c.ClientCerts = leafCerts(r.TLS.VerifiedChains)
+	}
+
+	return c
+}
+
+// CallerFromContext gives the caller of the grpc call with the context
+func CallerFromContext(ctx context.Context) Caller {
+	c := Caller{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(AuthorizationKey); len(values) > 0 {
+			c.Authorization = values[0]
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return c
+	}
+	if p.Addr != nil {
+		c.RemoteIP = parseIP(p.Addr.String())
+	}
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		c.ClientCerts = leafCerts(tlsInfo.State.VerifiedChains)
+	}
+
+	return c
+}
+
+func parseIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	return net.ParseIP(host)
+}
+
+func leafCerts(chains [][]*x509.Certificate) []*x509.Certificate {
+	certs := make([]*x509.Certificate, 0, len(chains))
+	for _, chain := range chains {
+		if len(chain) > 0 {
+			certs = append(certs, chain[0])
+		}
+	}
+
+	return certs
+}
+
+// Any allows callers allowed by any of the authenticators
+func Any(authenticators ...Authenticator) Authenticator {
+	return func(c Caller) bool {
+		for _, a := range authenticators {
+			if a(c) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// BearerToken allows callers with "Authorization: Bearer <token>" for any of the tokens
+func BearerToken(tokens ...string) Authenticator {
+	return func(c Caller) bool {
+		const prefix = "Bearer "
+		if !strings.HasPrefix(c.Authorization, prefix) {
+			return false
+		}
+
+		return containsSecret(tokens, strings.TrimPrefix(c.Authorization, prefix))
+	}
+}
+
+// BasicAuth allows callers with basic auth credentials of any of the users, the map key is the user name, the value is the password
+func BasicAuth(users map[string]string) Authenticator {
+	return func(c Caller) bool {
+		const prefix = "Basic "
+		if !strings.HasPrefix(c.Authorization, prefix) {
+			return false
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(c.Authorization, prefix))
+		if err != nil {
+			return false
+		}
+
+		user, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return false
+		}
+
+		expectedPassword, ok := users[user]
+		if !ok {
+			return false
+		}
+
+		return containsSecret([]string{expectedPassword}, password)
+	}
+}
+
+// ClientCert allows callers with a verified client certificate with any of the common names, e.g. for mutual TLS,
+// without common names any verified certificate is allowed
+func ClientCert(commonNames ...string) Authenticator {
+	return func(c Caller) bool {
+		for _, cert := range c.ClientCerts {
+			if len(commonNames) == 0 {
+				return true
+			}
+			for _, cn := range commonNames {
+				if cert.Subject.CommonName == cn {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
+
+// AllowCIDRs allows callers from any of the networks, e.g. "10.0.0.0/8" or "127.0.0.1/32"
+func AllowCIDRs(cidrs ...string) (Authenticator, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+
+	return func(c Caller) bool {
+		if c.RemoteIP == nil {
+			return false
+		}
+		for _, network := range networks {
+			if network.Contains(c.RemoteIP) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+func containsSecret(secrets []string, given string) bool {
+	isFound := false
+	for _, secret := range secrets {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(given)) == 1 {
+			isFound = true
+		}
+	}
+
+	return isFound
+}
+
+type detailsKey struct{}
+
+// WithholdDetails gives a context which tells handlers to respond without details, e.g. for anonymous callers
+func WithholdDetails(ctx context.Context) context.Context {
+	return context.WithValue(ctx, detailsKey{}, true)
+}
+
+// IsDetailAllowed is false if details are withheld with the context
+func IsDetailAllowed(ctx context.Context) bool {
+	isWithheld, _ := ctx.Value(detailsKey{}).(bool)
+
+	return !isWithheld
+}
+
+// NewMiddleware gives middleware which withholds details from callers not allowed by the authenticator, see IsDetailAllowed
+func NewMiddleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !authenticator(CallerFromRequest(r)) {
+				r = r.WithContext(WithholdDetails(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NewRequireMiddleware gives middleware which responds with 401 to callers not allowed by the authenticator
+func NewRequireMiddleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !authenticator(CallerFromRequest(r)) {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
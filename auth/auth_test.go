@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestAuthenticators(t *testing.T) {
+	cidrs, err := AllowCIDRs("10.0.0.0/8", "127.0.0.1/32")
+	assert.NoError(t, err)
+
+	monitoringCert := &x509.Certificate{Subject: pkix.Name{CommonName: "monitoring"}}
+
+	testCases := []struct {
+		name            string
+		authenticator   Authenticator
+		caller          Caller
+		expectedAllowed bool
+	}{
+		{name: "valid bearer token", authenticator: BearerToken("t1", "t2"), caller: Caller{Authorization: "Bearer t2"}, expectedAllowed: true},
+		{name: "wrong bearer token", authenticator: BearerToken("t1"), caller: Caller{Authorization: "Bearer t2"}},
+		{name: "basic instead of bearer", authenticator: BearerToken("t1"), caller: Caller{Authorization: "Basic t1"}},
+		{name: "valid basic auth", authenticator: BasicAuth(map[string]string{"ops": "secret"}), caller: Caller{Authorization: "Basic b3BzOnNlY3JldA=="}, expectedAllowed: true},
+		{name: "wrong basic password", authenticator: BasicAuth(map[string]string{"ops": "other"}), caller: Caller{Authorization: "Basic b3BzOnNlY3JldA=="}},
+		{name: "invalid basic encoding", authenticator: BasicAuth(map[string]string{"ops": "secret"}), caller: Caller{Authorization: "Basic %%%"}},
+		{name: "client cert with allowed name", authenticator: ClientCert("monitoring"), caller: Caller{ClientCerts: []*x509.Certificate{monitoringCert}}, expectedAllowed: true},
+		{name: "any client cert", authenticator: ClientCert(), caller: Caller{ClientCerts: []*x509.Certificate{monitoringCert}}, expectedAllowed: true},
+		{name: "client cert with other name", authenticator: ClientCert("admin"), caller: Caller{ClientCerts: []*x509.Certificate{monitoringCert}}},
+		{name: "no client cert", authenticator: ClientCert()},
+		{name: "allowed network", authenticator: cidrs, caller: Caller{RemoteIP: net.ParseIP("10.1.2.3")}, expectedAllowed: true},
+		{name: "other network", authenticator: cidrs, caller: Caller{RemoteIP: net.ParseIP("192.168.1.1")}},
+		{name: "unknown ip", authenticator: cidrs},
+		{name: "any", authenticator: Any(BearerToken("t1"), cidrs), caller: Caller{RemoteIP: net.ParseIP("127.0.0.1")}, expectedAllowed: true},
+		{name: "none of any", authenticator: Any(BearerToken("t1"), cidrs), caller: Caller{Authorization: "Bearer t2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedAllowed, tc.authenticator(tc.caller))
+		})
+	}
+}
+
+func TestAllowCIDRsInvalid(t *testing.T) {
+	_, err := AllowCIDRs("10.0.0.0")
+	assert.EqualError(t, err, `invalid cidr "10.0.0.0": invalid CIDR address: 10.0.0.0`)
+}
+
+func TestCallerFromRequest(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "monitoring"}}
+	r := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	r.RemoteAddr = "10.1.2.3:40000"
+	r.Header.Set("Authorization", "Bearer t1")
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+
+	c := CallerFromRequest(r)
+	assert.Equal(t, "Bearer t1", c.Authorization)
+	assert.Equal(t, "10.1.2.3", c.RemoteIP.String())
+	assert.Equal(t, []*x509.Certificate{cert}, c.ClientCerts)
+}
+
+func TestCallerFromContext(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "monitoring"}}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationKey, "Bearer t1"))
+	ctx = peer.NewContext(ctx, &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 40000},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+
+	c := CallerFromContext(ctx)
+	assert.Equal(t, "Bearer t1", c.Authorization)
+	assert.Equal(t, "10.1.2.3", c.RemoteIP.String())
+	assert.Equal(t, []*x509.Certificate{cert}, c.ClientCerts)
+
+	assert.Equal(t, Caller{}, CallerFromContext(context.Background()))
+}
+
+func TestMiddlewares(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsDetailAllowed(r.Context()) {
+			_, _ = w.Write([]byte("details"))
+		}
+	})
+	authenticator := BearerToken("t1")
+
+	testCases := []struct {
+		name          string
+		middleware    func(http.Handler) http.Handler
+		authorization string
+		expectedCode  int
+		expectedBody  string
+	}{
+		{name: "details for allowed caller", middleware: NewMiddleware(authenticator), authorization: "Bearer t1", expectedCode: http.StatusOK, expectedBody: "details"},
+		{name: "no details for anonymous caller", middleware: NewMiddleware(authenticator), expectedCode: http.StatusOK},
+		{name: "required for allowed caller", middleware: NewRequireMiddleware(authenticator), authorization: "Bearer t1", expectedCode: http.StatusOK, expectedBody: "details"},
+		{name: "required for anonymous caller", middleware: NewRequireMiddleware(authenticator), expectedCode: http.StatusUnauthorized, expectedBody: "Unauthorized\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			tc.middleware(handler).ServeHTTP(rec, r)
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
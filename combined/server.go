@@ -0,0 +1,141 @@
+package combined
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	hrGrpc "github.com/breathbath/healthReadyChecks/grpc"
+	"github.com/breathbath/healthReadyChecks/logging"
+	"github.com/breathbath/healthReadyChecks/rest"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// NewHandler gives http.Handler which passes gRPC requests to the grpcServer and all other requests to the httpHandler,
+// HTTP/1.1 and h2c (HTTP/2 without TLS) requests are accepted
+func NewHandler(grpcServer *grpc.Server, httpHandler http.Handler) http.Handler {
+	return newHandler(grpcServer, httpHandler, &http2.Server{}, nil)
+}
+
+// newHandler gives the handler of NewHandler serving HTTP/2 with h2s, active counts requests in progress if it's not nil
+func newHandler(grpcServer *grpc.Server, httpHandler http.Handler, h2s *http2.Server, active *int64) http.Handler {
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if active != nil {
+			atomic.AddInt64(active, 1)
+			defer atomic.AddInt64(active, -1)
+		}
+
+		if isGRPCRequest(r) {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		httpHandler.ServeHTTP(w, r)
+	}), h2s)
+}
+
+func isGRPCRequest(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// Serve serves health and ready services of the grpcServer and endpoints of the restServer on the listener until the context is done,
+// then stops gracefully and returns nil, e.g. for pods which can expose only one port, opts are given to grpc.NewServer.
+// Like with hrGrpc.Server.Serve in-progress calls and requests are awaited for hrGrpc.DefaultGracePeriod before they are cancelled,
+// the address is sent to the channel of rest.WithStarted once the server is listening
+func Serve(ctx context.Context, lis net.Listener, grpcServer hrGrpc.Server, restServer rest.Server, opts ...grpc.ServerOption) error {
+	if grpcServer.HealthChecker == nil || grpcServer.ReadyChecker == nil {
+		lis.Close()
+		return errors.New("both health and ready checkers are required")
+	}
+	err := restServer.Validate()
+	if err != nil {
+		lis.Close()
+		return err
+	}
+
+	baseSrv := grpc.NewServer(opts...)
+	grpcServer.Register(baseSrv)
+
+	addr := lis.Addr()
+	httpServer := &http.Server{}
+	h2s := &http2.Server{}
+	// lets httpServer.Shutdown send GOAWAY to h2c connections
+	err = http2.ConfigureServer(httpServer, h2s)
+	if err != nil {
+		lis.Close()
+		return err
+	}
+	var active int64
+	httpServer.Handler = newHandler(baseSrv, restServer.Handler(), h2s, &active)
+
+	logging.L.InfoF("Starting health/ready GRPC and REST server at %s", addr)
+
+	serveDone := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		select {
+		case <-ctx.Done():
+		case <-serveDone:
+			return
+		}
+		logging.L.InfoF("Exiting health GRPC and REST server at %s", addr)
+		stopGracefully(httpServer, baseSrv, &active, hrGrpc.DefaultGracePeriod)
+	}()
+
+	restServer.NotifyStarted(ctx, addr)
+
+	err = httpServer.Serve(lis)
+	close(serveDone)
+	<-stopped
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// stopGracefully stops accepting connections and waits for in-progress requests until the grace period is over, then cancels them,
+// grpc.Server.GracefulStop can't be used as it doesn't support connections of grpc.Server.ServeHTTP
+func stopGracefully(httpServer *http.Server, baseSrv *grpc.Server, active *int64, gracePeriod time.Duration) {
+	deadline := time.Now().Add(gracePeriod)
+	shutdownCtx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	err := httpServer.Shutdown(shutdownCtx)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		logging.L.ErrorF(err.Error())
+	}
+
+	// h2c connections are hijacked from httpServer, so Shutdown doesn't wait for their requests
+	if !awaitRequests(shutdownCtx, active) {
+		logging.L.WarnF("GRPC calls and REST requests didn't complete in %v, will cancel them", gracePeriod)
+	}
+
+	httpServer.Close()
+	baseSrv.Stop()
+}
+
+// awaitRequests waits until no request is in progress, false if the context is done before
+func awaitRequests(ctx context.Context, active *int64) bool {
+	ticker := time.NewTicker(time.Millisecond * 10)
+	defer ticker.Stop()
+
+	for atomic.LoadInt64(active) > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,193 @@
+package combined
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	hrGrpc "github.com/breathbath/healthReadyChecks/grpc"
+	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
+	"github.com/breathbath/healthReadyChecks/rest"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type healthCheckerMock struct{}
+
+func (hcm healthCheckerMock) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	return true, ""
+}
+
+func (hcm healthCheckerMock) SubscribeToUnhealthyChange(sf func(reason string)) {}
+
+type readyCheckerMock struct{}
+
+func (rcm readyCheckerMock) IsReady(ctx context.Context) (isReady bool, err error) {
+	return false, errors.New("db is down")
+}
+
+func TestServe(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	addr := lis.Addr().String()
+
+	grpcServer := hrGrpc.Server{HealthChecker: healthCheckerMock{}, ReadyChecker: readyCheckerMock{}}
+	started := make(chan net.Addr, 1)
+	restServer := rest.WithReady(rest.WithHealth(rest.Server{}, healthCheckerMock{}), readyCheckerMock{}, time.Second)
+	restServer = rest.WithStarted(restServer, started)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- Serve(ctx, lis, grpcServer, restServer)
+	}()
+
+	select {
+	case startedAddr := <-started:
+		assert.Equal(t, addr, startedAddr.String())
+	case <-time.After(time.Second):
+		assert.Fail(t, "started address is not sent")
+	}
+
+	t.Run("http1", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("http://%s/healthz", addr))
+		assert.NoError(t, err)
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 1, resp.ProtoMajor)
+	})
+
+	t.Run("h2c", func(t *testing.T) {
+		cl := http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		}}
+		resp, err := cl.Get(fmt.Sprintf("http://%s/readyz", addr))
+		assert.NoError(t, err)
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, 2, resp.ProtoMajor)
+		assert.Equal(t, "db is down", string(body))
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		assert.NoError(t, err)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		healthResp, err := healthProto.NewHealthClient(conn).Check(context.Background(), &healthProto.HealthCheckRequest{})
+		assert.NoError(t, err)
+		if err == nil {
+			assert.Equal(t, healthProto.HealthCheckResponse_SERVING, healthResp.Status)
+		}
+
+		_, err = readyProto.NewReadyClient(conn).Ready(context.Background(), &readyProto.ReadyRequest{})
+		assert.EqualError(t, err, "rpc error: code = Unknown desc = db is down")
+	})
+
+	cancel()
+	assert.NoError(t, <-serveErr)
+}
+
+func TestServeWithoutCheckers(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	restServer := rest.WithHealth(rest.Server{}, healthCheckerMock{})
+	err = Serve(context.Background(), lis, hrGrpc.Server{HealthChecker: healthCheckerMock{}}, restServer)
+	assert.EqualError(t, err, "both health and ready checkers are required")
+
+	_, err = lis.Accept()
+	assert.Error(t, err, "listener should be closed")
+}
+
+type blockingReadyChecker struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (brc blockingReadyChecker) IsReady(ctx context.Context) (isReady bool, err error) {
+	close(brc.started)
+	select {
+	case <-brc.release:
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func TestServeStopsGracefully(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	checker := blockingReadyChecker{started: make(chan struct{}), release: make(chan struct{})}
+	grpcServer := hrGrpc.Server{HealthChecker: healthCheckerMock{}, ReadyChecker: checker}
+	restServer := rest.WithHealth(rest.Server{}, healthCheckerMock{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- Serve(ctx, lis, grpcServer, restServer)
+	}()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.NoError(t, err)
+	if err != nil {
+		cancel()
+		return
+	}
+	defer conn.Close()
+
+	readyErr := make(chan error, 1)
+	go func() {
+		resp, err := readyProto.NewReadyClient(conn).Ready(context.Background(), &readyProto.ReadyRequest{})
+		if err == nil && !resp.Status {
+			err = errors.New("not ready")
+		}
+		readyErr <- err
+	}()
+	<-checker.started
+
+	cancel()
+	select {
+	case err := <-serveErr:
+		assert.FailNow(t, "server stopped before the call completed", "%v", err)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	close(checker.release)
+	assert.NoError(t, <-readyErr)
+	assert.NoError(t, <-serveErr)
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/combined"
+	"github.com/breathbath/healthReadyChecks/errs"
+	hrGrpc "github.com/breathbath/healthReadyChecks/grpc"
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/breathbath/healthReadyChecks/ready"
+	"github.com/breathbath/healthReadyChecks/rest"
+	"github.com/breathbath/healthReadyChecks/sidecar"
+	"github.com/breathbath/healthReadyChecks/sleep"
+)
+
+const (
+	defaultTimeUnit    = time.Minute
+	defaultTestTimeout = time.Second
+	defaultMaxRetries  = 1
+)
+
+// Components servers and checkers built from a Config, parts which are not configured are nil
+type Components struct {
+	// ErrStream should be injected into the application logic to report errors to ErrsListener
+	ErrStream    errs.ErrStream
+	ErrsListener *health.ErrsListener
+	TestChecker  *ready.TestChecker
+	Aggregator   *sidecar.Aggregator
+	REST         *rest.Server
+	RESTPort     int
+	GRPC         *hrGrpc.Server
+	GRPCPort     int
+	cfg          Config
+	// lock guards cfg and reconfiguration of checkers, it's a pointer as Components are given by value
+	lock *sync.Mutex
+}
+
+// Build creates servers and checkers described by the config
+func Build(cfg Config) (Components, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return Components{}, err
+	}
+
+	c := Components{cfg: cfg, lock: &sync.Mutex{}}
+	var healthChecker health.Checker
+	var readyChecker ready.Checker
+
+	if cfg.Health != nil {
+		c.ErrStream = errs.NewErrStream(cfg.Health.ErrStreamBuffer)
+		c.ErrsListener = health.NewErrsListener(cfg.Health.MaxErrors, cfg.Health.timeUnit(), c.ErrStream)
+		healthChecker = c.ErrsListener
+	}
+
+	if cfg.Ready != nil {
+		tc := buildTestChecker(*cfg.Ready)
+		c.TestChecker = &tc
+		readyChecker = tc
+	}
+
+	if cfg.Sidecar != nil {
+		c.Aggregator, err = sidecar.NewAggregator(buildTargets(*cfg.Sidecar))
+		if err != nil {
+			return Components{}, FieldError{Field: "sidecar.targets", Reason: err.Error()}
+		}
+		healthChecker = c.Aggregator
+		readyChecker = c.Aggregator
+	}
+
+	if cfg.REST != nil {
+		srv := rest.WithPathPrefix(rest.Server{}, cfg.REST.PathPrefix)
+		srv = rest.WithHost(srv, cfg.REST.Host)
+		if healthChecker != nil {
+			srv = rest.WithHealth(srv, healthChecker)
+		}
+		if readyChecker != nil {
+			srv = rest.WithReady(srv, readyChecker, cfg.REST.ReadyTimeout)
+		}
+		if c.Aggregator != nil {
+			srv = rest.WithHandler(srv, "/targets", sidecar.NewTargetsHandler(c.Aggregator))
+		}
+		c.REST = &srv
+		c.RESTPort = cfg.REST.Port
+	}
+
+	if cfg.GRPC != nil {
+		c.GRPC = &hrGrpc.Server{
+			HealthChecker: healthChecker,
+			ReadyChecker:  readyChecker,
+			ReadyTimeout:  cfg.GRPC.ReadyTimeout,
+		}
+		c.GRPCPort = cfg.GRPC.Port
+	}
+
+	return c, nil
+}
+
+func (hc HealthConfig) timeUnit() time.Duration {
+	if hc.TimeUnit == 0 {
+		return defaultTimeUnit
+	}
+
+	return hc.TimeUnit
+}
+
+func (rc ReadyConfig) maxRetries() int {
+	if rc.MaxRetries == 0 {
+		return defaultMaxRetries
+	}
+
+	return rc.MaxRetries
+}
+
+func buildTestChecker(rc ReadyConfig) ready.TestChecker {
+	opts := []ready.CheckerOption{ready.WithMaxConcurrency(rc.MaxConcurrency)}
+	if rc.SingleFlight {
+		opts = append(opts, ready.WithSingleFlight())
+	}
+
+	return ready.NewTestChecker(buildTests(rc), rc.maxRetries(), rc.RetryInterval, sleep.RuntimeSleeper{}, opts...)
+}
+
+func buildTests(rc ReadyConfig) []ready.Test {
+	tests := make([]ready.Test, 0, len(rc.Tests))
+	for _, tc := range rc.Tests {
+		timeout := tc.Timeout
+		if timeout == 0 {
+			timeout = defaultTestTimeout
+		}
+
+		var test ready.Test
+		switch tc.Type {
+		case TestTypeTCP:
+			test = ready.NewTCPTest(tc.Name, tc.Address, timeout)
+		case TestTypeHTTP:
+			test = ready.NewHTTPTest(tc.Name, tc.Address, timeout)
+		case TestTypeGRPCHealth:
+			test = hrGrpc.NewHealthTest(tc.Name, tc.Address, timeout)
+		case TestTypeGRPCReady:
+			test = hrGrpc.NewReadyTest(tc.Name, tc.Address, timeout)
+		}
+		test.FailureThreshold = tc.FailureThreshold
+		test.SuccessThreshold = tc.SuccessThreshold
+		test.BreakerThreshold = tc.BreakerThreshold
+		test.BreakerCooldown = tc.BreakerCooldown
+		test.DependsOn = tc.DependsOn
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+func buildTargets(sc SidecarConfig) []sidecar.Target {
+	targets := make([]sidecar.Target, 0, len(sc.Targets))
+	for _, tc := range sc.Targets {
+		targets = append(targets, sidecar.Target{
+			Name:     tc.Name,
+			Protocol: sidecar.Protocol(tc.Protocol),
+			Kind:     sidecar.Kind(tc.Kind),
+			Address:  tc.Address,
+			Interval: tc.Interval,
+			Timeout:  tc.Timeout,
+		})
+	}
+
+	return targets
+}
+
+func (c *Components) restHost() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cfg.REST == nil {
+		return ""
+	}
+
+	return c.cfg.REST.Host
+}
+
+// Start runs background checkers and configured servers until the context is done,
+// rest and grpc servers with the same port share one listener, see combined.Serve, returns the first server error
+func (c *Components) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if c.ErrsListener != nil {
+		go c.ErrsListener.Start(ctx)
+	}
+	if c.Aggregator != nil {
+		go c.Aggregator.Start(ctx)
+	}
+
+	errChan := make(chan error, 2)
+	wg := &sync.WaitGroup{}
+
+	if c.REST != nil && c.GRPC != nil && c.RESTPort == c.GRPCPort {
+		host := c.restHost()
+		lis, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(c.GRPCPort)))
+		if err != nil {
+			return err
+		}
+
+		return combined.Serve(ctx, lis, *c.GRPC, *c.REST)
+	}
+
+	if c.REST != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errChan <- c.REST.Start(ctx, c.RESTPort)
+		}()
+	}
+
+	if c.GRPC != nil {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", c.GRPCPort))
+		if err != nil {
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errChan <- c.GRPC.Serve(ctx, lis)
+		}()
+	}
+
+	err := <-errChan
+	cancel()
+	wg.Wait()
+
+	return err
+}
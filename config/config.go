@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config declarative description of health and ready servers and checks, can be read from yaml or json
+type Config struct {
+	REST    *RESTConfig    `yaml:"rest" json:"rest"`
+	GRPC    *GRPCConfig    `yaml:"grpc" json:"grpc"`
+	Health  *HealthConfig  `yaml:"health" json:"health"`
+	Ready   *ReadyConfig   `yaml:"ready" json:"ready"`
+	Sidecar *SidecarConfig `yaml:"sidecar" json:"sidecar"`
+}
+
+// RESTConfig settings of rest.Server
+type RESTConfig struct {
+	Port         int           `yaml:"port" json:"port"`
+	ReadyTimeout time.Duration `yaml:"readyTimeout" json:"readyTimeout"`
+	// PathPrefix see rest.WithPathPrefix, e.g. /internal
+	PathPrefix string `yaml:"pathPrefix" json:"pathPrefix"`
+	// Host see rest.WithHost, e.g. 127.0.0.1
+	Host string `yaml:"host" json:"host"`
+}
+
+// GRPCConfig settings of grpc.Server
+type GRPCConfig struct {
+	Port         int           `yaml:"port" json:"port"`
+	ReadyTimeout time.Duration `yaml:"readyTimeout" json:"readyTimeout"`
+}
+
+// HealthConfig thresholds of health.ErrsListener
+type HealthConfig struct {
+	MaxErrors       int           `yaml:"maxErrors" json:"maxErrors"`
+	TimeUnit        time.Duration `yaml:"timeUnit" json:"timeUnit"`
+	ErrStreamBuffer int           `yaml:"errStreamBuffer" json:"errStreamBuffer"`
+}
+
+// ReadyConfig settings of ready.TestChecker
+type ReadyConfig struct {
+	MaxRetries    int           `yaml:"maxRetries" json:"maxRetries"`
+	RetryInterval time.Duration `yaml:"retryInterval" json:"retryInterval"`
+	Tests         []TestConfig  `yaml:"tests" json:"tests"`
+	// SingleFlight and MaxConcurrency see ready.WithSingleFlight and ready.WithMaxConcurrency
+	SingleFlight   bool `yaml:"singleFlight" json:"singleFlight"`
+	MaxConcurrency int  `yaml:"maxConcurrency" json:"maxConcurrency"`
+}
+
+// TestConfig one of the built-in ready test types
+type TestConfig struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is one of tcp, http, grpc-health, grpc-ready
+	Type string `yaml:"type" json:"type"`
+	// Address is host:port for tcp and grpc tests or a full url for http tests
+	Address string        `yaml:"address" json:"address"`
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+	// FailureThreshold and SuccessThreshold see ready.Test
+	FailureThreshold int `yaml:"failureThreshold" json:"failureThreshold"`
+	SuccessThreshold int `yaml:"successThreshold" json:"successThreshold"`
+	// BreakerThreshold and BreakerCooldown see ready.Test
+	BreakerThreshold int           `yaml:"breakerThreshold" json:"breakerThreshold"`
+	BreakerCooldown  time.Duration `yaml:"breakerCooldown" json:"breakerCooldown"`
+	// DependsOn names of tests which must be ready before this test is executed
+	DependsOn []string `yaml:"dependsOn" json:"dependsOn"`
+}
+
+// SidecarConfig upstream targets of sidecar.Aggregator
+type SidecarConfig struct {
+	Targets []TargetConfig `yaml:"targets" json:"targets"`
+}
+
+// TargetConfig description of a sidecar.Target
+type TargetConfig struct {
+	Name     string        `yaml:"name" json:"name"`
+	Protocol string        `yaml:"protocol" json:"protocol"`
+	Kind     string        `yaml:"kind" json:"kind"`
+	Address  string        `yaml:"address" json:"address"`
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	Timeout  time.Duration `yaml:"timeout" json:"timeout"`
+}
+
+// Load reads the yaml or json config file, interpolates environment variables and validates the result
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Parse interpolates environment variables in data, decodes it as yaml or json and validates the result.
+// Both $VAR and ${VAR} are replaced, ${VAR:-default} gives default if VAR is empty, $$ gives a literal $.
+func Parse(data []byte) (Config, error) {
+	expanded := os.Expand(string(data), lookupEnv)
+
+	// json is a subset of yaml, so a single decoder serves both formats
+	dec := yaml.NewDecoder(bytes.NewBufferString(expanded))
+	dec.KnownFields(true)
+
+	cfg := Config{}
+	err := dec.Decode(&cfg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, err
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func lookupEnv(name string) string {
+	if name == "$" {
+		return "$"
+	}
+
+	defaultVal := ""
+	if i := strings.Index(name, ":-"); i >= 0 {
+		name, defaultVal = name[:i], name[i+2:]
+	}
+
+	val := os.Getenv(name)
+	if val == "" {
+		return defaultVal
+	}
+
+	return val
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const yamlConfig = `
+rest:
+  port: ${HRC_REST_PORT}
+  readyTimeout: 2s
+grpc:
+  port: ${HRC_GRPC_PORT:-9090}
+health:
+  maxErrors: 3
+  timeUnit: 30s
+  errStreamBuffer: 10
+ready:
+  maxRetries: 2
+  retryInterval: 500ms
+  tests:
+    - name: db
+      type: tcp
+      address: ${HRC_DB_HOST}:5432
+    - name: api
+      type: http
+      address: http://api/readyz?token=$$secret
+      timeout: 3s
+`
+
+const jsonConfig = `{
+  "rest": {"port": 8080},
+  "sidecar": {
+    "targets": [
+      {"name": "api", "protocol": "http", "kind": "health", "address": "http://127.0.0.1:8081/healthz", "interval": "5s"},
+      {"name": "worker", "protocol": "grpc", "kind": "ready", "address": "127.0.0.1:9091", "timeout": "2s"}
+    ]
+  }
+}`
+
+func TestParseYAML(t *testing.T) {
+	t.Setenv("HRC_REST_PORT", "8099")
+	t.Setenv("HRC_DB_HOST", "postgres")
+
+	cfg, err := Parse([]byte(yamlConfig))
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	assert.Equal(t, &RESTConfig{Port: 8099, ReadyTimeout: 2 * time.Second}, cfg.REST)
+	assert.Equal(t, &GRPCConfig{Port: 9090}, cfg.GRPC)
+	assert.Equal(t, &HealthConfig{MaxErrors: 3, TimeUnit: 30 * time.Second, ErrStreamBuffer: 10}, cfg.Health)
+	assert.Equal(t, &ReadyConfig{
+		MaxRetries:    2,
+		RetryInterval: 500 * time.Millisecond,
+		Tests: []TestConfig{
+			{Name: "db", Type: TestTypeTCP, Address: "postgres:5432"},
+			{Name: "api", Type: TestTypeHTTP, Address: "http://api/readyz?token=$secret", Timeout: 3 * time.Second},
+		},
+	}, cfg.Ready)
+	assert.Nil(t, cfg.Sidecar)
+}
+
+func TestParseJSON(t *testing.T) {
+	cfg, err := Parse([]byte(jsonConfig))
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	assert.Equal(t, &RESTConfig{Port: 8080}, cfg.REST)
+	assert.Equal(t, &SidecarConfig{Targets: []TargetConfig{
+		{Name: "api", Protocol: "http", Kind: "health", Address: "http://127.0.0.1:8081/healthz", Interval: 5 * time.Second},
+		{Name: "worker", Protocol: "grpc", Kind: "ready", Address: "127.0.0.1:9091", Timeout: 2 * time.Second},
+	}}, cfg.Sidecar)
+}
+
+func TestParseUnknownField(t *testing.T) {
+	_, err := Parse([]byte("rest:\n  prot: 8080\nhealth:\n  maxErrors: 1\n"))
+	assert.EqualError(t, err, "yaml: unmarshal errors:\n  line 2: field prot not found in type config.RESTConfig")
+}
+
+func TestValidationErrors(t *testing.T) {
+	_, err := Parse([]byte(`
+rest:
+  port: 70000
+  pathPrefix: internal
+health:
+  maxErrors: 0
+ready:
+  tests:
+    - name: db
+      type: udp
+      address: db:5432
+    - name: db
+      type: http
+`))
+
+	assert.EqualError(
+		t,
+		err,
+		`rest.port: port 70000 is out of range 0-65535, rest.pathPrefix: must start with /, health.maxErrors: must be positive, `+
+			`ready.tests[0].type: unknown type "udp", expected one of tcp, http, grpc-health, grpc-ready, `+
+			`ready.tests[1].address: is required, ready.tests[1].name: duplicate name "db"`,
+	)
+
+	validationErr, ok := err.(ValidationError)
+	assert.True(t, ok)
+	if !ok {
+		return
+	}
+	assert.Equal(t, FieldError{Field: "rest.port", Reason: "port 70000 is out of range 0-65535"}, validationErr[0])
+}
+
+func TestValidationOfDependencies(t *testing.T) {
+	_, err := Parse([]byte(`
+rest:
+  port: 8080
+ready:
+  tests:
+    - name: tcp
+      type: tcp
+      address: db:5432
+      dependsOn: [table]
+    - name: table
+      type: grpc-ready
+      address: db:5432
+      dependsOn: [tcp]
+`))
+
+	assert.EqualError(t, err, "ready.tests: dependency cycle tcp -> table -> tcp")
+}
+
+func TestValidationOfServersAndChecks(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         Config
+		expectedErr string
+	}{
+		{
+			name:        "empty",
+			cfg:         Config{},
+			expectedErr: "rest: either rest or grpc server should be configured, health: either health, ready or sidecar checks should be configured",
+		},
+		{
+			name: "sidecar with health",
+			cfg: Config{
+				REST:    &RESTConfig{},
+				Health:  &HealthConfig{MaxErrors: 1},
+				Sidecar: &SidecarConfig{Targets: []TargetConfig{{Name: "a", Protocol: "http", Kind: "health", Address: "http://a"}}},
+			},
+			expectedErr: "sidecar: cannot be combined with health or ready checks",
+		},
+		{
+			name: "grpc without ready",
+			cfg: Config{
+				GRPC:   &GRPCConfig{},
+				Health: &HealthConfig{MaxErrors: 1},
+			},
+			expectedErr: "grpc: requires both health and ready checks to be configured",
+		},
+		{
+			name: "bad sidecar target",
+			cfg: Config{
+				REST:    &RESTConfig{},
+				Sidecar: &SidecarConfig{Targets: []TargetConfig{{Name: "a", Protocol: "tcp", Kind: "live", Interval: -1}}},
+			},
+			expectedErr: `sidecar.targets[0].protocol: unknown protocol "tcp", expected http or grpc, ` +
+				`sidecar.targets[0].kind: unknown kind "live", expected health or ready, ` +
+				`sidecar.targets[0].address: is required, sidecar.targets[0].interval: must not be negative`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.cfg.Validate(), tc.expectedErr)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checks.json")
+	err := os.WriteFile(path, []byte(jsonConfig), 0o600)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg.Sidecar)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+
+	err = os.WriteFile(path, []byte(`{"rest": {"port": -1}, "health": {"maxErrors": 1}}`), 0o600)
+	assert.NoError(t, err)
+	_, err = Load(path)
+	assert.EqualError(t, err, "invalid config "+path+": rest.port: port -1 is out of range 0-65535")
+}
+
+func TestBuild(t *testing.T) {
+	t.Setenv("HRC_REST_PORT", "8099")
+	t.Setenv("HRC_DB_HOST", "127.0.0.1")
+
+	cfg, err := Parse([]byte(yamlConfig))
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	c, err := Build(cfg)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	assert.NotNil(t, c.ErrStream)
+	assert.Equal(t, 10, cap(c.ErrStream))
+	assert.NotNil(t, c.ErrsListener)
+	assert.NotNil(t, c.TestChecker)
+	assert.Nil(t, c.Aggregator)
+	assert.NotNil(t, c.REST)
+	assert.Equal(t, 8099, c.RESTPort)
+	assert.NotNil(t, c.GRPC)
+	assert.Equal(t, 9090, c.GRPCPort)
+	assert.Equal(t, c.ErrsListener, c.GRPC.HealthChecker)
+
+	isHealthy, _ := c.ErrsListener.IsHealthy()
+	assert.True(t, isHealthy)
+}
+
+func TestBuildAndStartSidecar(t *testing.T) {
+	cfg, err := Parse([]byte(`
+rest:
+  port: 0
+grpc:
+  port: 0
+sidecar:
+  targets:
+    - name: api
+      protocol: http
+      kind: ready
+      address: http://127.0.0.1:1
+`))
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	c, err := Build(cfg)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	assert.NotNil(t, c.Aggregator)
+	assert.Equal(t, c.Aggregator, c.GRPC.ReadyChecker)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	_ = c.Start(ctx)
+
+	isReady, err := c.Aggregator.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.Error(t, err)
+}
+
+func TestStartWithSharedPort(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	cfg, err := Parse([]byte(fmt.Sprintf(`
+rest:
+  port: %d
+  host: 127.0.0.1
+grpc:
+  port: %d
+health:
+  maxErrors: 1
+ready:
+  tests:
+    - name: self
+      type: tcp
+      address: 127.0.0.1:%d
+`, port, port, port)))
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	c, err := Build(cfg)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- c.Start(ctx)
+	}()
+
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		resp, err = http.Get(fmt.Sprintf("http://%s/readyz", addr))
+		if err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.NoError(t, err)
+	if err == nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	assert.NoError(t, err)
+	if err == nil {
+		defer conn.Close()
+		healthResp, err := healthProto.NewHealthClient(conn).Check(context.Background(), &healthProto.HealthCheckRequest{})
+		assert.NoError(t, err)
+		if err == nil {
+			assert.Equal(t, healthProto.HealthCheckResponse_SERVING, healthResp.Status)
+		}
+	}
+
+	cancel()
+	assert.NoError(t, <-startErr)
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+// Apply reconfigures the running checkers: health thresholds, ready tests, retries and the retry interval are replaced atomically,
+// changes of other fields require a restart and are rejected with a ValidationError, it's safe to call it concurrently, e.g. with WatchFile
+func (c *Components) Apply(cfg Config) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	err := cfg.Validate()
+	if err != nil {
+		return err
+	}
+
+	v := &validator{}
+	if !reflect.DeepEqual(c.cfg.REST, cfg.REST) {
+		v.fail("rest", "changes require a restart")
+	}
+	if !reflect.DeepEqual(c.cfg.GRPC, cfg.GRPC) {
+		v.fail("grpc", "changes require a restart")
+	}
+	if !reflect.DeepEqual(c.cfg.Sidecar, cfg.Sidecar) {
+		v.fail("sidecar", "changes require a restart")
+	}
+	if (c.cfg.Health == nil) != (cfg.Health == nil) {
+		v.fail("health", "cannot be added or removed without a restart")
+	} else if cfg.Health != nil && c.cfg.Health.ErrStreamBuffer != cfg.Health.ErrStreamBuffer {
+		v.fail("health.errStreamBuffer", "changes require a restart")
+	}
+	if (c.cfg.Ready == nil) != (cfg.Ready == nil) {
+		v.fail("ready", "cannot be added or removed without a restart")
+	} else if cfg.Ready != nil {
+		if c.cfg.Ready.SingleFlight != cfg.Ready.SingleFlight {
+			v.fail("ready.singleFlight", "changes require a restart")
+		}
+		if c.cfg.Ready.MaxConcurrency != cfg.Ready.MaxConcurrency {
+			v.fail("ready.maxConcurrency", "changes require a restart")
+		}
+	}
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	if cfg.Health != nil {
+		c.ErrsListener.Reconfigure(cfg.Health.MaxErrors, cfg.Health.timeUnit())
+	}
+	if cfg.Ready != nil {
+		c.TestChecker.Reconfigure(buildTests(*cfg.Ready), cfg.Ready.maxRetries(), cfg.Ready.RetryInterval)
+	}
+	c.cfg = cfg
+
+	return nil
+}
+
+// WatchFile reloads the config file into the components when its modification time changes, which is checked every pollInterval,
+// or when the process receives SIGHUP, invalid configs are logged and the previous config stays active
+func (c *Components) WatchFile(ctx context.Context, path string, pollInterval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	c.watchFile(ctx, path, pollInterval, hup)
+}
+
+func (c *Components) watchFile(ctx context.Context, path string, pollInterval time.Duration, hup <-chan os.Signal) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastModTime := modTime(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logging.L.InfoF("Received SIGHUP, will reload config %s", path)
+			lastModTime = modTime(path)
+			c.reload(path)
+		case <-ticker.C:
+			currentModTime := modTime(path)
+			if currentModTime.Equal(lastModTime) {
+				continue
+			}
+			logging.L.InfoF("Config %s was changed, will reload it", path)
+			lastModTime = currentModTime
+			c.reload(path)
+		}
+	}
+}
+
+func (c *Components) reload(path string) {
+	cfg, err := Load(path)
+	if err == nil {
+		err = c.Apply(cfg)
+	}
+
+	if err != nil {
+		logging.L.ErrorF("Failed to reload config %s, will keep the previous one: %v", path, err)
+		return
+	}
+
+	logging.L.InfoF("Config %s is reloaded", path)
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
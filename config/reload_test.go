@@ -0,0 +1,175 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildComponents(t *testing.T, data string) Components {
+	cfg, err := Parse([]byte(data))
+	assert.NoError(t, err)
+
+	c, err := Build(cfg)
+	assert.NoError(t, err)
+
+	return c
+}
+
+func TestApply(t *testing.T) {
+	c := buildComponents(t, "rest:\n  port: 8099\nhealth:\n  maxErrors: 1\nready:\n  tests: []\n")
+
+	isReady, err := c.TestChecker.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+
+	cfg, err := Parse([]byte(`
+rest:
+  port: 8099
+health:
+  maxErrors: 5
+ready:
+  tests:
+    - name: db
+      type: tcp
+      address: 127.0.0.1:1
+`))
+	assert.NoError(t, err)
+
+	err = c.Apply(cfg)
+	assert.NoError(t, err)
+
+	isReady, err = c.TestChecker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.Error(t, err)
+	if err != nil {
+		assert.Contains(t, err.Error(), "Readiness probe failed for db")
+	}
+}
+
+func TestApplyRequiresRestart(t *testing.T) {
+	c := buildComponents(t, "rest:\n  port: 8099\nhealth:\n  maxErrors: 1\n  errStreamBuffer: 1\n")
+
+	cfg, err := Parse([]byte("rest:\n  port: 8098\nhealth:\n  maxErrors: 1\n  errStreamBuffer: 2\nready:\n  tests: []\n"))
+	assert.NoError(t, err)
+
+	err = c.Apply(cfg)
+	assert.EqualError(
+		t,
+		err,
+		"rest: changes require a restart, health.errStreamBuffer: changes require a restart, ready: cannot be added or removed without a restart",
+	)
+
+	err = c.Apply(Config{})
+	assert.Error(t, err)
+}
+
+func TestApplyRequiresRestartForReadyConcurrency(t *testing.T) {
+	c := buildComponents(t, "rest:\n  port: 8099\nready:\n  tests: []\n")
+
+	cfg, err := Parse([]byte("rest:\n  port: 8099\nready:\n  singleFlight: true\n  maxConcurrency: 2\n  tests: []\n"))
+	assert.NoError(t, err)
+
+	err = c.Apply(cfg)
+	assert.EqualError(t, err, "ready.singleFlight: changes require a restart, ready.maxConcurrency: changes require a restart")
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checks.yaml")
+	err := os.WriteFile(path, []byte("rest:\n  port: 8099\nready:\n  tests: []\n"), 0o600)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	c, err := Build(cfg)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hup := make(chan os.Signal)
+	done := make(chan bool)
+	go func() {
+		c.watchFile(ctx, path, time.Millisecond*10, hup)
+		done <- true
+	}()
+
+	// an invalid config is ignored
+	err = os.WriteFile(path, []byte("rest:\n  port: 8099\nready:\n  maxRetries: -1\n"), 0o600)
+	assert.NoError(t, err)
+	err = os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 50)
+
+	isReady, _ := c.TestChecker.IsReady(context.Background())
+	assert.True(t, isReady)
+
+	// the file is reloaded on SIGHUP even if it was not modified
+	err = os.WriteFile(path, []byte("rest:\n  port: 8099\nready:\n  tests:\n    - name: db\n      type: tcp\n      address: 127.0.0.1:1\n"), 0o600)
+	assert.NoError(t, err)
+	err = os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	hup <- syscall.SIGHUP
+	time.Sleep(time.Millisecond * 50)
+
+	isReady, _ = c.TestChecker.IsReady(context.Background())
+	assert.False(t, isReady)
+
+	// modified file is reloaded
+	err = os.WriteFile(path, []byte("rest:\n  port: 8099\nready:\n  tests: []\n"), 0o600)
+	assert.NoError(t, err)
+	err = os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second))
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 50)
+
+	isReady, _ = c.TestChecker.IsReady(context.Background())
+	assert.True(t, isReady)
+
+	cancel()
+	<-done
+}
+
+func TestApplyDuringWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checks.yaml")
+	data := []byte("rest:\n  port: 8099\nready:\n  tests: []\n")
+	err := os.WriteFile(path, data, 0o600)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	c := buildComponents(t, string(data))
+	cfg, err := Parse(data)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hup := make(chan os.Signal)
+	done := make(chan bool)
+	go func() {
+		c.watchFile(ctx, path, time.Millisecond, hup)
+		done <- true
+	}()
+
+	for i := 1; i <= 20; i++ {
+		err = os.Chtimes(path, time.Now(), time.Now().Add(time.Duration(i)*time.Second))
+		assert.NoError(t, err)
+		assert.NoError(t, c.Apply(cfg))
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/breathbath/healthReadyChecks/ready"
+	"github.com/breathbath/healthReadyChecks/sidecar"
+)
+
+// Test types which can be used in TestConfig.Type
+const (
+	TestTypeTCP        = "tcp"
+	TestTypeHTTP       = "http"
+	TestTypeGRPCHealth = "grpc-health"
+	TestTypeGRPCReady  = "grpc-ready"
+)
+
+// FieldError points at the config field which has an invalid value
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+// Error error interface implementation
+func (fe FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", fe.Field, fe.Reason)
+}
+
+// ValidationError collection of all invalid fields of a config
+type ValidationError []FieldError
+
+// Error error interface implementation
+func (ve ValidationError) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		msgs = append(msgs, fe.Error())
+	}
+
+	return strings.Join(msgs, ", ")
+}
+
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) fail(field, reasonTemplate string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Reason: fmt.Sprintf(reasonTemplate, args...)})
+}
+
+func (v *validator) checkPort(field string, port int) {
+	if port < 0 || port > 65535 {
+		v.fail(field, "port %d is out of range 0-65535", port)
+	}
+}
+
+func (v *validator) checkNames(field string, names []string) {
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		itemField := fmt.Sprintf("%s[%d].name", field, i)
+		if name == "" {
+			v.fail(itemField, "is required")
+			continue
+		}
+		if seen[name] {
+			v.fail(itemField, "duplicate name %q", name)
+		}
+		seen[name] = true
+	}
+}
+
+// Validate checks all fields of the config, returns ValidationError listing every invalid field
+func (c Config) Validate() error {
+	v := &validator{}
+
+	if c.REST == nil && c.GRPC == nil {
+		v.fail("rest", "either rest or grpc server should be configured")
+	}
+	if c.Health == nil && c.Ready == nil && c.Sidecar == nil {
+		v.fail("health", "either health, ready or sidecar checks should be configured")
+	}
+	if c.Sidecar != nil && (c.Health != nil || c.Ready != nil) {
+		v.fail("sidecar", "cannot be combined with health or ready checks")
+	}
+	if c.GRPC != nil && c.Sidecar == nil && (c.Health == nil || c.Ready == nil) {
+		v.fail("grpc", "requires both health and ready checks to be configured")
+	}
+
+	if c.REST != nil {
+		v.checkPort("rest.port", c.REST.Port)
+		if c.REST.ReadyTimeout < 0 {
+			v.fail("rest.readyTimeout", "must not be negative")
+		}
+		if c.REST.PathPrefix != "" && !strings.HasPrefix(c.REST.PathPrefix, "/") {
+			v.fail("rest.pathPrefix", "must start with /")
+		}
+	}
+
+	if c.GRPC != nil {
+		v.checkPort("grpc.port", c.GRPC.Port)
+		if c.GRPC.ReadyTimeout < 0 {
+			v.fail("grpc.readyTimeout", "must not be negative")
+		}
+	}
+
+	if c.Health != nil {
+		if c.Health.MaxErrors <= 0 {
+			v.fail("health.maxErrors", "must be positive")
+		}
+		if c.Health.TimeUnit < 0 {
+			v.fail("health.timeUnit", "must not be negative")
+		}
+		if c.Health.ErrStreamBuffer < 0 {
+			v.fail("health.errStreamBuffer", "must not be negative")
+		}
+	}
+
+	if c.Ready != nil {
+		c.Ready.validate(v)
+	}
+
+	if c.Sidecar != nil {
+		c.Sidecar.validate(v)
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	return nil
+}
+
+func (rc ReadyConfig) validate(v *validator) {
+	if rc.MaxRetries < 0 {
+		v.fail("ready.maxRetries", "must not be negative")
+	}
+	if rc.RetryInterval < 0 {
+		v.fail("ready.retryInterval", "must not be negative")
+	}
+	if rc.MaxConcurrency < 0 {
+		v.fail("ready.maxConcurrency", "must not be negative")
+	}
+
+	names := make([]string, 0, len(rc.Tests))
+	deps := make([]ready.Test, 0, len(rc.Tests))
+	for i, tc := range rc.Tests {
+		names = append(names, tc.Name)
+		deps = append(deps, ready.Test{Name: tc.Name, DependsOn: tc.DependsOn})
+		field := fmt.Sprintf("ready.tests[%d]", i)
+
+		switch tc.Type {
+		case TestTypeTCP, TestTypeHTTP, TestTypeGRPCHealth, TestTypeGRPCReady:
+		case "":
+			v.fail(field+".type", "is required")
+		default:
+			v.fail(field+".type", "unknown type %q, expected one of %s, %s, %s, %s", tc.Type, TestTypeTCP, TestTypeHTTP, TestTypeGRPCHealth, TestTypeGRPCReady)
+		}
+		if tc.Address == "" {
+			v.fail(field+".address", "is required")
+		}
+		if tc.Timeout < 0 {
+			v.fail(field+".timeout", "must not be negative")
+		}
+		if tc.FailureThreshold < 0 {
+			v.fail(field+".failureThreshold", "must not be negative")
+		}
+		if tc.SuccessThreshold < 0 {
+			v.fail(field+".successThreshold", "must not be negative")
+		}
+		if tc.BreakerThreshold < 0 {
+			v.fail(field+".breakerThreshold", "must not be negative")
+		}
+		if tc.BreakerCooldown < 0 {
+			v.fail(field+".breakerCooldown", "must not be negative")
+		}
+	}
+	v.checkNames("ready.tests", names)
+
+	err := ready.ValidateDependencies(deps)
+	if err != nil {
+		v.fail("ready.tests", "%v", err)
+	}
+}
+
+func (sc SidecarConfig) validate(v *validator) {
+	if len(sc.Targets) == 0 {
+		v.fail("sidecar.targets", "at least one target is required")
+	}
+
+	names := make([]string, 0, len(sc.Targets))
+	for i, tc := range sc.Targets {
+		names = append(names, tc.Name)
+		field := fmt.Sprintf("sidecar.targets[%d]", i)
+
+		protocol := sidecar.Protocol(tc.Protocol)
+		if protocol != sidecar.ProtocolHTTP && protocol != sidecar.ProtocolGRPC {
+			v.fail(field+".protocol", "unknown protocol %q, expected %s or %s", tc.Protocol, sidecar.ProtocolHTTP, sidecar.ProtocolGRPC)
+		}
+		kind := sidecar.Kind(tc.Kind)
+		if kind != sidecar.KindHealth && kind != sidecar.KindReady {
+			v.fail(field+".kind", "unknown kind %q, expected %s or %s", tc.Kind, sidecar.KindHealth, sidecar.KindReady)
+		}
+		if tc.Address == "" {
+			v.fail(field+".address", "is required")
+		}
+		if tc.Interval < 0 {
+			v.fail(field+".interval", "must not be negative")
+		}
+		if tc.Timeout < 0 {
+			v.fail(field+".timeout", "must not be negative")
+		}
+	}
+	v.checkNames("sidecar.targets", names)
+}
@@ -0,0 +1,163 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// Filter decides if a payload should be delivered to a Broker subscriber
+type Filter func(ep ErrPayload) bool
+
+// ByCategory passes payloads of any of the categories
+func ByCategory(categories ...string) Filter {
+	return func(ep ErrPayload) bool {
+		for _, c := range categories {
+			if ep.Category == c {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// ByError passes payloads with errors matching the target with errors.Is
+func ByError(target error) Filter {
+	return func(ep ErrPayload) bool {
+		return errors.Is(ep.Err, target)
+	}
+}
+
+// ByType passes payloads with errors which errors.As can assign to the target, the target is a pointer as it's given to errors.As,
+// e.g. ByType(new(*net.OpError)), its value is never modified
+func ByType(target interface{}) Filter {
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Ptr {
+		panic("errs: target must be a non-nil pointer")
+	}
+
+	return func(ep ErrPayload) bool {
+		// a new target is allocated per call to stay safe for concurrent use
+		return ep.Err != nil && errors.As(ep.Err, reflect.New(targetType.Elem()).Interface())
+	}
+}
+
+type subscription struct {
+	stream  ErrStream
+	sender  *NonBlockingStream
+	filters []Filter
+}
+
+func (s subscription) accepts(ep ErrPayload) bool {
+	for _, f := range s.filters {
+		if !f(ep) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Broker fans out errors to multiple subscribers, e.g. a per component ErrsListener, a global one and an alerting sink,
+// every subscriber has an independent buffer which never blocks the Broker, overflows are handled with the OverflowPolicy,
+// held back payloads of slow subscribers are retried in the background like with NonBlockingStream
+type Broker struct {
+	input         ErrStream
+	done          chan struct{}
+	policy        OverflowPolicy
+	lock          sync.RWMutex
+	subscriptions []subscription
+	isClosed      bool
+}
+
+// NewBroker constructor for Broker, buffer is the size of the incoming errors buffer
+func NewBroker(buffer int, policy OverflowPolicy) *Broker {
+	return &Broker{
+		input:  NewErrStream(buffer),
+		done:   make(chan struct{}),
+		policy: policy,
+		lock:   sync.RWMutex{},
+	}
+}
+
+// Send wraps sending to the broker, blocks until the broker takes the error or is stopped
+func (b *Broker) Send(err error) {
+	b.SendPayload(NewErrPayload(err, ""))
+}
+
+// SendWithCategory sends the err with the category
+func (b *Broker) SendWithCategory(err error, category string) {
+	b.SendPayload(NewErrPayload(err, category))
+}
+
+// SendPayload sends a prepared payload to the broker, payloads sent after the broker is stopped are dropped
+func (b *Broker) SendPayload(ep ErrPayload) {
+	select {
+	case b.input <- ep:
+	case <-b.done:
+	}
+}
+
+// Subscribe gives a stream with the buffer size which receives payloads passing all filters, the stream is closed when the broker stops
+func (b *Broker) Subscribe(buffer int, filters ...Filter) ErrStream {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	stream := NewErrStream(buffer)
+	if b.isClosed {
+		close(stream)
+		return stream
+	}
+
+	b.subscriptions = append(b.subscriptions, subscription{
+		stream:  stream,
+		sender:  NewNonBlockingStream(stream, b.policy),
+		filters: filters,
+	})
+
+	return stream
+}
+
+// Start delivers incoming errors to subscribers until the context is done, then sends held back payloads which fit into
+// subscription streams and closes them
+func (b *Broker) Start(ctx context.Context) {
+	defer b.close()
+
+	for {
+		select {
+		case ep := <-b.input:
+			b.publish(ep)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Broker) publish(ep ErrPayload) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for _, s := range b.subscriptions {
+		if s.accepts(ep) {
+			s.sender.SendPayload(ep)
+		}
+	}
+}
+
+func (b *Broker) close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.isClosed {
+		return
+	}
+	b.isClosed = true
+	close(b.done)
+
+	for _, s := range b.subscriptions {
+		s.sender.Close()
+		close(s.stream)
+	}
+}
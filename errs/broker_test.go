@@ -0,0 +1,155 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errConnRefused = errors.New("connection refused")
+
+type queryError struct {
+	query string
+}
+
+func (qe *queryError) Error() string {
+	return "query failed: " + qe.query
+}
+
+func receive(t *testing.T, stream ErrStream, count int) []ErrPayload {
+	payloads := make([]ErrPayload, 0, count)
+	for i := 0; i < count; i++ {
+		select {
+		case ep := <-stream:
+			payloads = append(payloads, ep)
+		case <-time.After(time.Second):
+			assert.Fail(t, "Timeout no error received in the error stream")
+			return payloads
+		}
+	}
+
+	return payloads
+}
+
+func TestBrokerFanOut(t *testing.T) {
+	b := NewBroker(0, DropNewest)
+
+	all := b.Subscribe(10)
+	db := b.Subscribe(10, ByCategory("db"))
+	refused := b.Subscribe(10, ByError(errConnRefused))
+	queries := b.Subscribe(10, ByType(new(*queryError)), ByCategory("db"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	b.Send(errors.New("some err"))
+	b.SendWithCategory(fmt.Errorf("dial: %w", errConnRefused), "db")
+	b.SendWithCategory(&queryError{query: "select 1"}, "db")
+	b.SendWithCategory(&queryError{query: "select 2"}, "cache")
+
+	assert.Equal(t, []string{"some err", "dial: connection refused", "query failed: select 1", "query failed: select 2"}, errMessages(receive(t, all, 4)))
+	assert.Equal(t, []string{"dial: connection refused", "query failed: select 1"}, errMessages(receive(t, db, 2)))
+	assert.Equal(t, []string{"dial: connection refused"}, errMessages(receive(t, refused, 1)))
+	assert.Equal(t, []string{"query failed: select 1"}, errMessages(receive(t, queries, 1)))
+
+	assert.Len(t, drain(all), 0)
+	assert.Len(t, drain(db), 0)
+	assert.Len(t, drain(refused), 0)
+	assert.Len(t, drain(queries), 0)
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	b := NewBroker(0, CountOnly)
+	slow := b.Subscribe(1)
+	fast := b.Subscribe(10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	for _, msg := range []string{"err1", "err2", "err3"} {
+		b.Send(errors.New(msg))
+	}
+
+	assert.Equal(t, []string{"err1", "err2", "err3"}, errMessages(receive(t, fast, 3)))
+	assert.Equal(t, []string{"err1"}, errMessages(receive(t, slow, 1)))
+
+	// counted errors reach the slow subscriber without further publishing
+	payloads := receive(t, slow, 1)
+	if assert.Len(t, payloads, 1) {
+		assert.ErrorIs(t, payloads[0].Err, ErrOverflow)
+		assert.Equal(t, 2, payloads[0].Weight())
+	}
+}
+
+func TestBrokerSlowSubscriberCoalesce(t *testing.T) {
+	b := NewBroker(0, Coalesce)
+	slow := b.Subscribe(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	for _, msg := range []string{"err1", "conn refused", "conn refused"} {
+		b.Send(errors.New(msg))
+	}
+
+	assert.Equal(t, []string{"err1"}, errMessages(receive(t, slow, 1)))
+	payloads := receive(t, slow, 1)
+	if assert.Len(t, payloads, 1) {
+		assert.Equal(t, "conn refused", payloads[0].Err.Error())
+		assert.Equal(t, 2, payloads[0].Weight())
+	}
+}
+
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker(0, DropNewest)
+	stream := b.Subscribe(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool)
+	go func() {
+		b.Start(ctx)
+		done <- true
+	}()
+	cancel()
+	<-done
+
+	_, ok := <-stream
+	assert.False(t, ok)
+
+	// sending to a stopped broker doesn't block
+	b.Send(errors.New("late err"))
+
+	_, ok = <-b.Subscribe(1)
+	assert.False(t, ok)
+}
+
+func TestByTypePanicsOnNonPointer(t *testing.T) {
+	assert.Panics(t, func() {
+		ByType(queryError{})
+	})
+	assert.Panics(t, func() {
+		ByType(nil)
+	})
+}
+
+func TestSenders(t *testing.T) {
+	stream := NewErrStream(2)
+	senders := []Sender{stream, NewNonBlockingStream(stream, DropNewest), NewBroker(0, DropNewest)}
+	assert.Len(t, senders, 3)
+
+	stream.SendWithCategory(errors.New("err1"), "db")
+	stream.SendPayload(ErrPayload{Err: errors.New("err2"), Count: 2})
+
+	payloads := drain(stream)
+	assert.Len(t, payloads, 2)
+	assert.Equal(t, "db", payloads[0].Category)
+	assert.True(t, payloads[0].Timestamp > 0)
+	assert.Equal(t, 2, payloads[1].Weight())
+}
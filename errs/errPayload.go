@@ -8,6 +8,34 @@ import (
 type ErrPayload struct {
 	Err       error
 	Timestamp int64
+	// Count amount of errors the payload stands for, e.g. for coalesced errors, 0 means a single error
+	Count int
+	// Category optional classification of the error, e.g. to filter errors of a Broker subscription
+	Category string
+}
+
+// Weight amount of errors the payload stands for, at least 1
+func (ep ErrPayload) Weight() int {
+	if ep.Count <= 0 {
+		return 1
+	}
+
+	return ep.Count
+}
+
+// NewErrPayload creates payload of the err with the current timestamp
+func NewErrPayload(err error, category string) ErrPayload {
+	return ErrPayload{
+		Err:       err,
+		Timestamp: time.Now().UTC().Unix(),
+		Category:  category,
+	}
+}
+
+// Sender abstracts errors sending, implemented by ErrStream, NonBlockingStream and Broker
+type Sender interface {
+	Send(err error)
+	SendPayload(ep ErrPayload)
 }
 
 // ErrStream wrapper for errors stream
@@ -21,9 +49,15 @@ func NewErrStream(buffer int) ErrStream {
 
 // Send wraps sending to err channel
 func (es ErrStream) Send(err error) {
-	ep := ErrPayload{
-		Err:       err,
-		Timestamp: time.Now().UTC().Unix(),
-	}
+	es <- NewErrPayload(err, "")
+}
+
+// SendWithCategory sends the err with the category
+func (es ErrStream) SendWithCategory(err error, category string) {
+	es <- NewErrPayload(err, category)
+}
+
+// SendPayload wraps sending of a prepared payload to err channel
+func (es ErrStream) SendPayload(ep ErrPayload) {
 	es <- ep
 }
@@ -0,0 +1,206 @@
+package errs
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrOverflow is sent by the CountOnly policy with a Count instead of errors which didn't fit into the stream, so they still count towards the health
+var ErrOverflow = errors.New("errors were dropped due to the error stream overflow")
+
+// retryInterval how often payloads which are held back by a full stream are retried
+const retryInterval = time.Millisecond * 100
+
+// OverflowPolicy tells NonBlockingStream what to do with errors which don't fit into a full stream
+type OverflowPolicy int
+
+const (
+	// DropNewest drops errors which don't fit into the stream, they are only counted in Dropped
+	DropNewest OverflowPolicy = iota
+	// DropOldest removes the oldest error from the stream to give place for the new one, removed errors are only counted in Dropped
+	DropOldest
+	// Coalesce accumulates identical errors into a single payload with a Count which is sent as soon as the stream has space,
+	// errors which differ from the accumulated one are dropped
+	Coalesce
+	// CountOnly accumulates errors which don't fit into the stream into a single ErrOverflow payload with a Count
+	// which is sent as soon as the stream has space, so they count towards the health without their details
+	CountOnly
+)
+
+// NonBlockingStream sends errors to an ErrStream without ever blocking the sender, errors which don't fit into the stream buffer
+// are handled according to the OverflowPolicy, held back payloads are retried in the background until they are sent or Close is called
+type NonBlockingStream struct {
+	stream    ErrStream
+	policy    OverflowPolicy
+	lock      sync.Mutex
+	coalesced *ErrPayload
+	overflow  *ErrPayload
+	retry     *time.Timer
+	isClosed  bool
+	dropped   uint64
+}
+
+// NewNonBlockingStream constructor for NonBlockingStream, the stream should be buffered for the policies to make sense
+func NewNonBlockingStream(stream ErrStream, policy OverflowPolicy) *NonBlockingStream {
+	return &NonBlockingStream{
+		stream: stream,
+		policy: policy,
+		lock:   sync.Mutex{},
+	}
+}
+
+// Send wraps non blocking sending to err channel
+func (s *NonBlockingStream) Send(err error) {
+	s.SendPayload(NewErrPayload(err, ""))
+}
+
+// SendWithCategory sends the err with the category
+func (s *NonBlockingStream) SendWithCategory(err error, category string) {
+	s.SendPayload(NewErrPayload(err, category))
+}
+
+// SendPayload sends the payload if the stream has space or applies the overflow policy, nil errors and errors sent after Close are ignored
+func (s *NonBlockingStream) SendPayload(ep ErrPayload) {
+	if ep.Err == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.isClosed {
+		return
+	}
+
+	if !s.flush() || !s.trySend(ep) {
+		s.handleOverflow(ep)
+	}
+	s.scheduleRetry()
+}
+
+// Flush sends held back Coalesce and CountOnly payloads if the stream has space, otherwise they are retried in the background
+func (s *NonBlockingStream) Flush() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.isClosed {
+		return
+	}
+
+	s.flush()
+	s.scheduleRetry()
+}
+
+// Close stops background retries, held back payloads are sent if the stream has space, otherwise they are dropped,
+// the stream itself is not closed, so it can be closed safely after Close
+func (s *NonBlockingStream) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.isClosed {
+		return
+	}
+
+	s.flush()
+	s.isClosed = true
+	if s.retry != nil {
+		s.retry.Stop()
+		s.retry = nil
+	}
+}
+
+// Dropped amount of errors which didn't reach the stream as themselves since the creation of the stream,
+// including errors counted in ErrOverflow payloads of CountOnly, errors which are delivered as part of a Coalesce payload are not dropped
+func (s *NonBlockingStream) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// flush sends held back payloads, false if some of them don't fit into the stream
+func (s *NonBlockingStream) flush() bool {
+	for _, pending := range []**ErrPayload{&s.coalesced, &s.overflow} {
+		if *pending == nil {
+			continue
+		}
+		if !s.trySend(**pending) {
+			return false
+		}
+		*pending = nil
+	}
+
+	return true
+}
+
+// scheduleRetry flushes held back payloads later, e.g. if a burst of errors is followed by silence
+func (s *NonBlockingStream) scheduleRetry() {
+	if s.coalesced == nil && s.overflow == nil || s.retry != nil {
+		return
+	}
+
+	s.retry = time.AfterFunc(retryInterval, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		if s.isClosed {
+			return
+		}
+		s.retry = nil
+		s.flush()
+		s.scheduleRetry()
+	})
+}
+
+func (s *NonBlockingStream) trySend(ep ErrPayload) bool {
+	select {
+	case s.stream <- ep:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *NonBlockingStream) handleOverflow(ep ErrPayload) {
+	switch s.policy {
+	case DropOldest:
+		select {
+		case oldest := <-s.stream:
+			s.drop(oldest)
+		default:
+		}
+		if !s.trySend(ep) {
+			s.drop(ep)
+		}
+	case Coalesce:
+		if s.coalesced == nil {
+			ep.Count = ep.Weight()
+			s.coalesced = &ep
+			return
+		}
+		if s.coalesced.Err.Error() != ep.Err.Error() {
+			s.drop(ep)
+			return
+		}
+		s.coalesced.Count += ep.Weight()
+		s.coalesced.Timestamp = ep.Timestamp
+	default:
+		s.drop(ep)
+	}
+}
+
+// drop discards the payload, CountOnly also counts it in the ErrOverflow payload
+func (s *NonBlockingStream) drop(ep ErrPayload) {
+	// an evicted ErrOverflow payload carries errors which were counted as dropped already
+	if !errors.Is(ep.Err, ErrOverflow) {
+		atomic.AddUint64(&s.dropped, uint64(ep.Weight()))
+	}
+
+	if s.policy != CountOnly {
+		return
+	}
+	if s.overflow == nil {
+		s.overflow = &ErrPayload{Err: ErrOverflow}
+	}
+	s.overflow.Count += ep.Weight()
+	s.overflow.Timestamp = ep.Timestamp
+}
@@ -0,0 +1,169 @@
+package errs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func drain(stream ErrStream) []ErrPayload {
+	payloads := make([]ErrPayload, 0)
+	for {
+		select {
+		case ep := <-stream:
+			payloads = append(payloads, ep)
+		default:
+			return payloads
+		}
+	}
+}
+
+func errMessages(payloads []ErrPayload) []string {
+	msgs := make([]string, 0, len(payloads))
+	for _, ep := range payloads {
+		msgs = append(msgs, ep.Err.Error())
+	}
+
+	return msgs
+}
+
+func TestNonBlockingSendWithoutListener(t *testing.T) {
+	s := NewNonBlockingStream(NewErrStream(0), DropNewest)
+	defer s.Close()
+
+	s.Send(errors.New("err1"))
+	s.Send(nil)
+
+	assert.Equal(t, uint64(1), s.Dropped())
+}
+
+func TestDropNewest(t *testing.T) {
+	stream := NewErrStream(2)
+	s := NewNonBlockingStream(stream, DropNewest)
+	defer s.Close()
+
+	for _, msg := range []string{"err1", "err2", "err3", "err4"} {
+		s.Send(errors.New(msg))
+	}
+
+	assert.Equal(t, uint64(2), s.Dropped())
+	assert.Equal(t, []string{"err1", "err2"}, errMessages(drain(stream)))
+
+	s.Flush()
+	assert.Len(t, drain(stream), 0)
+}
+
+func TestDropOldest(t *testing.T) {
+	stream := NewErrStream(2)
+	s := NewNonBlockingStream(stream, DropOldest)
+	defer s.Close()
+
+	for _, msg := range []string{"err1", "err2", "err3", "err4"} {
+		s.Send(errors.New(msg))
+	}
+
+	assert.Equal(t, uint64(2), s.Dropped())
+	assert.Equal(t, []string{"err3", "err4"}, errMessages(drain(stream)))
+
+	s.Flush()
+	assert.Len(t, drain(stream), 0)
+}
+
+func TestCoalesce(t *testing.T) {
+	stream := NewErrStream(1)
+	s := NewNonBlockingStream(stream, Coalesce)
+	defer s.Close()
+
+	for _, msg := range []string{"err1", "conn refused", "conn refused", "other", "conn refused"} {
+		s.Send(errors.New(msg))
+	}
+	// only "other" is discarded, repeated "conn refused" errors are delivered in the coalesced payload
+	assert.Equal(t, uint64(1), s.Dropped())
+
+	payloads := drain(stream)
+	assert.Equal(t, []string{"err1"}, errMessages(payloads))
+
+	s.Flush()
+	payloads = drain(stream)
+	assert.Equal(t, []string{"conn refused"}, errMessages(payloads))
+	assert.Equal(t, 3, payloads[0].Weight())
+
+	s.Flush()
+	assert.Len(t, drain(stream), 0)
+
+	s.Send(errors.New("err2"))
+	assert.Equal(t, []string{"err2"}, errMessages(drain(stream)))
+	assert.Equal(t, uint64(1), s.Dropped())
+}
+
+func TestCountOnly(t *testing.T) {
+	stream := NewErrStream(1)
+	s := NewNonBlockingStream(stream, CountOnly)
+	defer s.Close()
+
+	for _, msg := range []string{"err1", "err2", "err3", "err4"} {
+		s.Send(errors.New(msg))
+	}
+	assert.Equal(t, uint64(3), s.Dropped())
+	assert.Equal(t, []string{"err1"}, errMessages(drain(stream)))
+
+	// accumulated payload is sent before the new error, which doesn't fit anymore and is counted again
+	s.Send(errors.New("err5"))
+	payloads := drain(stream)
+	assert.Len(t, payloads, 1)
+	assert.ErrorIs(t, payloads[0].Err, ErrOverflow)
+	assert.Equal(t, 3, payloads[0].Weight())
+	assert.Equal(t, uint64(4), s.Dropped())
+
+	s.Flush()
+	payloads = drain(stream)
+	assert.Len(t, payloads, 1)
+	assert.Equal(t, 1, payloads[0].Weight())
+}
+
+func TestRetryAfterBurst(t *testing.T) {
+	for _, policy := range []OverflowPolicy{Coalesce, CountOnly} {
+		stream := NewErrStream(1)
+		s := NewNonBlockingStream(stream, policy)
+
+		for _, msg := range []string{"err1", "err2", "err2"} {
+			s.Send(errors.New(msg))
+		}
+
+		total := 0
+		for total < 3 {
+			select {
+			case ep := <-stream:
+				total += ep.Weight()
+			case <-time.After(time.Second):
+				assert.FailNow(t, "held back errors are not sent", "policy %d, received %d errors", policy, total)
+			}
+		}
+		assert.Equal(t, 3, total, "policy %d", policy)
+		s.Close()
+	}
+}
+
+func TestClose(t *testing.T) {
+	stream := NewErrStream(1)
+	s := NewNonBlockingStream(stream, Coalesce)
+
+	s.Send(errors.New("err1"))
+	s.Send(errors.New("err2"))
+	s.Close()
+
+	assert.Equal(t, []string{"err1"}, errMessages(drain(stream)))
+	time.Sleep(retryInterval * 2)
+	assert.Len(t, drain(stream), 0)
+
+	s.Send(errors.New("err3"))
+	assert.Len(t, drain(stream), 0)
+}
+
+func TestPayloadWeight(t *testing.T) {
+	assert.Equal(t, 1, ErrPayload{}.Weight())
+	assert.Equal(t, 1, ErrPayload{Count: -1}.Weight())
+	assert.Equal(t, 5, ErrPayload{Count: 5}.Weight())
+}
@@ -0,0 +1,37 @@
+package errs
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// CategoryPanic category of errors created from recovered panics
+const CategoryPanic = "panic"
+
+// PanicError error created from a recovered panic, can be matched with ByType(new(*PanicError))
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// NewPanicError creates PanicError with the stack of the calling goroutine, should be called in the deferred recover func
+func NewPanicError(value interface{}) *PanicError {
+	return &PanicError{
+		Value: value,
+		Stack: debug.Stack(),
+	}
+}
+
+// Error error implementation
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", pe.Value)
+}
+
+// Unwrap gives the panic value if it's an error
+func (pe *PanicError) Unwrap() error {
+	if err, ok := pe.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
@@ -4,10 +4,11 @@ go 1.18
 
 require (
 	github.com/golang/protobuf v1.4.0
-	github.com/gorilla/mux v1.7.3
 	github.com/stretchr/testify v1.7.2
+	golang.org/x/net v0.0.0-20200501053045-e0ff5e5a1de5
 	google.golang.org/grpc v1.29.1
 	google.golang.org/protobuf v1.21.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -15,10 +16,8 @@ require (
 	github.com/kr/text v0.2.0 // indirect
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/net v0.0.0-20200501053045-e0ff5e5a1de5 // indirect
 	golang.org/x/sys v0.0.0-20200501145240-bc7a7d42d5c3 // indirect
 	golang.org/x/text v0.3.2 // indirect
 	google.golang.org/genproto v0.0.0-20200430143042-b979b6f78d84 // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -7,6 +7,7 @@ import (
 
 	"github.com/breathbath/healthReadyChecks/logging"
 	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
+	"github.com/breathbath/healthReadyChecks/ready"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	healthProto "google.golang.org/grpc/health/grpc_health_v1"
@@ -15,9 +16,19 @@ import (
 
 // CheckHealth triggers a health check against health GRPC
 func CheckHealth(addr, name string) error {
+	return CheckHealthWithTimeout(addr, name, time.Second)
+}
+
+// CheckHealthWithTimeout triggers a health check against health GRPC, timeoutDuration limits both dialing and the health rpc
+func CheckHealthWithTimeout(addr, name string, timeoutDuration time.Duration) error {
+	return CheckHealthContext(context.Background(), addr, name, timeoutDuration)
+}
+
+// CheckHealthContext triggers a health check against health GRPC like CheckHealthWithTimeout, dialing and the health rpc stop when the context is done
+func CheckHealthContext(parentCtx context.Context, addr, name string, timeoutDuration time.Duration) error {
 	logging.L.DebugF("Will check health of %s at %s", name, addr)
 
-	ctx, cancel1 := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel1 := context.WithTimeout(parentCtx, timeoutDuration)
 	defer cancel1()
 	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure())
 	if err != nil {
@@ -25,8 +36,7 @@ func CheckHealth(addr, name string) error {
 	}
 	defer conn.Close()
 
-	timeoutDuration := time.Second
-	ctx2, cancel2 := context.WithTimeout(context.Background(), timeoutDuration)
+	ctx2, cancel2 := context.WithTimeout(parentCtx, timeoutDuration)
 	defer cancel2()
 
 	cl := healthProto.NewHealthClient(conn)
@@ -61,9 +71,19 @@ func CheckHealth(addr, name string) error {
 
 // CheckReady triggers a ready check against ready GRPC
 func CheckReady(addr, name string) error {
+	return CheckReadyWithTimeout(addr, name, time.Second)
+}
+
+// CheckReadyWithTimeout triggers a ready check against ready GRPC, timeoutDuration limits both dialing and the ready rpc
+func CheckReadyWithTimeout(addr, name string, timeoutDuration time.Duration) error {
+	return CheckReadyContext(context.Background(), addr, name, timeoutDuration)
+}
+
+// CheckReadyContext triggers a ready check against ready GRPC like CheckReadyWithTimeout, dialing and the ready rpc stop when the context is done
+func CheckReadyContext(parentCtx context.Context, addr, name string, timeoutDuration time.Duration) error {
 	logging.L.DebugF("Will check ready of %s at %s", name, addr)
 
-	ctx, cancel1 := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel1 := context.WithTimeout(parentCtx, timeoutDuration)
 	defer cancel1()
 	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure())
 	if err != nil {
@@ -71,8 +91,7 @@ func CheckReady(addr, name string) error {
 	}
 	defer conn.Close()
 
-	timeoutDuration := time.Second
-	ctx2, cancel2 := context.WithTimeout(context.Background(), timeoutDuration)
+	ctx2, cancel2 := context.WithTimeout(parentCtx, timeoutDuration)
 	defer cancel2()
 
 	cl := readyProto.NewReadyClient(conn)
@@ -101,3 +120,29 @@ func CheckReady(addr, name string) error {
 	logging.L.DebugF("Ready check of %s OK, resp: %v", name, resp)
 	return nil
 }
+
+// NewHealthTest gives ready.Test which checks health of a GRPC server at addr, e.g. to make readiness depend on another service
+func NewHealthTest(name, addr string, timeout time.Duration) ready.Test {
+	return ready.Test{
+		TestFunc: func() error {
+			return CheckHealthWithTimeout(addr, name, timeout)
+		},
+		ContextFunc: func(ctx context.Context) error {
+			return CheckHealthContext(ctx, addr, name, timeout)
+		},
+		Name: name,
+	}
+}
+
+// NewReadyTest gives ready.Test which checks readiness of a GRPC server at addr
+func NewReadyTest(name, addr string, timeout time.Duration) ready.Test {
+	return ready.Test{
+		TestFunc: func() error {
+			return CheckReadyWithTimeout(addr, name, timeout)
+		},
+		ContextFunc: func(ctx context.Context) error {
+			return CheckReadyContext(ctx, addr, name, timeout)
+		},
+		Name: name,
+	}
+}
@@ -1,15 +1,19 @@
 package grpc
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"testing"
 	"time"
 
+	"github.com/breathbath/healthReadyChecks/health"
 	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestHealthChecker(t *testing.T) {
@@ -177,3 +181,72 @@ func startGRPC(srv Server) (addr string, baseSrv *grpc.Server, err error) {
 		return lis.Addr().String(), baseSrv, nil
 	}
 }
+
+func TestHealthAndReadyTests(t *testing.T) {
+	s := Server{
+		HealthChecker: &healthCheckerMock{isHealthy: true},
+		ReadyChecker:  &readyCheckerMock{isReady: false},
+	}
+
+	address, baseSrv, err := startGRPC(s)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	defer baseSrv.Stop()
+
+	healthTest := NewHealthTest("some health", address, time.Second)
+	assert.Equal(t, "some health", healthTest.Name)
+	assert.NoError(t, healthTest.TestFunc())
+
+	readyTest := NewReadyTest("some ready", address, time.Second)
+	assert.Equal(t, "some ready", readyTest.Name)
+	assert.EqualError(t, readyTest.TestFunc(), "GRPC of some ready is not ready yet: false")
+}
+
+func TestHealthStatusHeader(t *testing.T) {
+	s := Server{
+		HealthChecker: &statusHealthCheckerMock{
+			healthCheckerMock: healthCheckerMock{isHealthy: false},
+			status:            health.Status{State: health.StateUnhealthy, ErrorCount: 3, Threshold: 2},
+		},
+	}
+
+	address, baseSrv, err := startGRPC(s)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	defer baseSrv.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	md := metadata.MD{}
+	resp, err := healthProto.NewHealthClient(conn).Check(
+		context.Background(),
+		&healthProto.HealthCheckRequest{Service: GRPCHealthName},
+		grpc.Header(&md),
+	)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	assert.Equal(t, healthProto.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	values := md.Get(HealthStatusHeader)
+	assert.Len(t, values, 1)
+	if len(values) == 0 {
+		return
+	}
+
+	st := health.Status{}
+	err = json.Unmarshal([]byte(values[0]), &st)
+	assert.NoError(t, err)
+	assert.Equal(t, health.StateUnhealthy, st.State)
+	assert.Equal(t, 3, st.ErrorCount)
+}
@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/breathbath/healthReadyChecks/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CategoryCode category of errors created from responses with the counted codes
+const CategoryCode = "grpc_code"
+
+type interceptorConfig struct {
+	codes    []codes.Code
+	recorder health.OutcomeRecorder
+}
+
+// InterceptorOption configures interceptors of NewErrorsUnaryInterceptor and NewErrorsStreamInterceptor
+type InterceptorOption func(ic *interceptorConfig)
+
+// WithCodes sends the errors of responses with any of the codes additionally to panics, e.g. codes.Internal, codes.Unavailable
+func WithCodes(cs ...codes.Code) InterceptorOption {
+	return func(ic *interceptorConfig) {
+		ic.codes = append(ic.codes, cs...)
+	}
+}
+
+// WithOutcomeRecorder records every call as a failure if an error is sent for it and as a success otherwise,
+// e.g. for health.RatioListener
+func WithOutcomeRecorder(recorder health.OutcomeRecorder) InterceptorOption {
+	return func(ic *interceptorConfig) {
+		ic.recorder = recorder
+	}
+}
+
+func newInterceptorConfig(opts []InterceptorOption) interceptorConfig {
+	ic := interceptorConfig{}
+	for _, opt := range opts {
+		opt(&ic)
+	}
+
+	return ic
+}
+
+func (ic interceptorConfig) report(sender errs.Sender, method string, err error) {
+	if err != nil {
+		code := status.Code(err)
+		for _, c := range ic.codes {
+			if c == code {
+				ic.send(sender, errs.NewErrPayload(fmt.Errorf("%s failed with code %s: %w", method, code, err), CategoryCode))
+				return
+			}
+		}
+	}
+
+	if ic.recorder != nil {
+		ic.recorder.RecordSuccess()
+	}
+}
+
+func (ic interceptorConfig) send(sender errs.Sender, ep errs.ErrPayload) {
+	sender.SendPayload(ep)
+	if ic.recorder != nil {
+		ic.recorder.RecordFailure(ep.Err)
+	}
+}
+
+// recoverPanic must be deferred directly to be able to recover
+func (ic interceptorConfig) recoverPanic(sender errs.Sender, method string, err *error) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+
+	logging.L.ErrorF("Recovered panic in %s: %v", method, rec)
+	ic.send(sender, errs.NewErrPayload(errs.NewPanicError(rec), errs.CategoryPanic))
+	*err = status.Errorf(codes.Internal, "panic in %s", method)
+}
+
+// NewErrorsUnaryInterceptor gives the interceptor which recovers panics of unary methods, responds with codes.Internal
+// and sends them to the sender as *errs.PanicError with errs.CategoryPanic
+func NewErrorsUnaryInterceptor(sender errs.Sender, opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	ic := newInterceptorConfig(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer ic.recoverPanic(sender, info.FullMethod, &err)
+
+		resp, err = handler(ctx, req)
+		ic.report(sender, info.FullMethod, err)
+
+		return resp, err
+	}
+}
+
+// NewErrorsStreamInterceptor gives the interceptor which recovers panics of streaming methods, responds with codes.Internal
+// and sends them to the sender as *errs.PanicError with errs.CategoryPanic
+func NewErrorsStreamInterceptor(sender errs.Sender, opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	ic := newInterceptorConfig(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer ic.recoverPanic(sender, info.FullMethod, &err)
+
+		err = handler(srv, ss)
+		ic.report(sender, info.FullMethod, err)
+
+		return err
+	}
+}
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+	"github.com/breathbath/healthReadyChecks/health"
+	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type panicHealthChecker struct{}
+
+// IsHealthy health.Checker implementation
+func (phc panicHealthChecker) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	panic("health is broken")
+}
+
+// SubscribeToUnhealthyChange health.Checker implementation
+func (phc panicHealthChecker) SubscribeToUnhealthyChange(sf func(reason string)) {
+	panic(errors.New("subscription is broken"))
+}
+
+func startBufconnGRPC(t *testing.T, srv Server, opts ...grpc.ServerOption) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	baseSrv := grpc.NewServer(opts...)
+	readyProto.RegisterReadyServer(baseSrv, srv)
+	healthProto.RegisterHealthServer(baseSrv, srv)
+	go func() {
+		_ = baseSrv.Serve(lis)
+	}()
+	t.Cleanup(baseSrv.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func TestErrorsInterceptorsRecoverPanics(t *testing.T) {
+	errStream := errs.NewErrStream(10)
+	conn := startBufconnGRPC(
+		t,
+		Server{HealthChecker: panicHealthChecker{}},
+		grpc.UnaryInterceptor(NewErrorsUnaryInterceptor(errStream)),
+		grpc.StreamInterceptor(NewErrorsStreamInterceptor(errStream)),
+	)
+	client := healthProto.NewHealthClient(conn)
+
+	_, err := client.Check(context.Background(), &healthProto.HealthCheckRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	ep := <-errStream
+	assert.Equal(t, errs.CategoryPanic, ep.Category)
+	assert.EqualError(t, ep.Err, "panic: health is broken")
+	pe := &errs.PanicError{}
+	assert.True(t, errors.As(ep.Err, &pe))
+	assert.NotEmpty(t, pe.Stack)
+
+	watchClient, err := client.Watch(context.Background(), &healthProto.HealthCheckRequest{})
+	assert.NoError(t, err)
+	_, err = watchClient.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	ep = <-errStream
+	assert.Equal(t, errs.CategoryPanic, ep.Category)
+	assert.EqualError(t, errors.Unwrap(ep.Err), "subscription is broken")
+}
+
+func TestErrorsInterceptorCodes(t *testing.T) {
+	errStream := errs.NewErrStream(10)
+	rl := health.NewRatioListener(health.RatioOptions{MaxFailureRatio: 0.3})
+	rcm := &readyCheckerMock{err: status.Error(codes.Unavailable, "db is down")}
+	conn := startBufconnGRPC(
+		t,
+		Server{ReadyChecker: rcm},
+		grpc.UnaryInterceptor(NewErrorsUnaryInterceptor(errStream, WithCodes(codes.Unavailable, codes.Internal), WithOutcomeRecorder(rl))),
+	)
+	client := readyProto.NewReadyClient(conn)
+
+	_, err := client.Ready(context.Background(), &readyProto.ReadyRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	ep := <-errStream
+	assert.Equal(t, CategoryCode, ep.Category)
+	assert.EqualError(t, ep.Err, "/readyProto.Ready/Ready failed with code Unavailable: rpc error: code = Unavailable desc = db is down")
+
+	rcm.err = errors.New("not counted")
+	_, err = client.Ready(context.Background(), &readyProto.ReadyRequest{})
+	assert.Equal(t, codes.Unknown, status.Code(err))
+
+	rcm.err = nil
+	rcm.isReady = true
+	_, err = client.Ready(context.Background(), &readyProto.ReadyRequest{})
+	assert.NoError(t, err)
+
+	assert.Len(t, errStream, 0)
+
+	isHealthy, reason := rl.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, reason, "Failure ratio 33.3% of 3 operations")
+}
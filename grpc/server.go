@@ -2,13 +2,18 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
+	"github.com/breathbath/healthReadyChecks/auth"
 	"github.com/breathbath/healthReadyChecks/health"
 	"github.com/breathbath/healthReadyChecks/logging"
 	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
 	"github.com/breathbath/healthReadyChecks/ready"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -18,15 +23,51 @@ const GRPCHealthName = "grpc.health.v1.GRPCHealth"
 // GRPCReadyName ready check id
 const GRPCReadyName = "grpc.health.v1.GRPCReady"
 
+// HealthStatusHeader response header with json encoded health.Status if the health checker implements health.StatusProvider
+const HealthStatusHeader = "x-health-status"
+
 // Server implements the https://github.com/grpc/grpc/blob/master/doc/health-checking.md health checking protocol
 type Server struct {
 	HealthChecker health.Checker
 	ReadyChecker  ready.Checker
+	// ReadyTimeout caps the deadline of Ready calls, 0 means only the deadline of the caller is used
+	ReadyTimeout time.Duration
+	// DetailsAuth gives failure reasons and the HealthStatusHeader only to callers it allows, e.g. by the authorization metadata, nil allows everybody
+	DetailsAuth auth.Authenticator
+}
+
+// errReasonWithheld is given to callers which are not allowed by DetailsAuth instead of the failure reason
+var errReasonWithheld = status.Error(codes.Unavailable, "reason withheld")
+
+func (s Server) isDetailAllowed(ctx context.Context) bool {
+	return s.DetailsAuth == nil || s.DetailsAuth(auth.CallerFromContext(ctx))
 }
 
 // Check implementation of pull model for the health status
 func (s Server) Check(ctx context.Context, req *healthProto.HealthCheckRequest) (*healthProto.HealthCheckResponse, error) {
-	return s.buildHealthResponse(req)
+	resp, err := s.buildHealthResponse(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if sp, ok := s.HealthChecker.(health.StatusProvider); ok && s.isDetailAllowed(ctx) {
+		s.sendHealthStatus(ctx, sp.Status())
+	}
+
+	return resp, nil
+}
+
+func (s Server) sendHealthStatus(ctx context.Context, st health.Status) {
+	statusBytes, err := json.Marshal(st)
+	if err != nil {
+		logging.L.ErrorF("Failed to encode health status: %v", err)
+		return
+	}
+
+	err = grpc.SetHeader(ctx, metadata.Pairs(HealthStatusHeader, string(statusBytes)))
+	if err != nil {
+		logging.L.DebugF("Failed to send health status header: %v", err)
+	}
 }
 
 // Watch implementation of push model for the health status changes
@@ -57,10 +98,19 @@ func (s Server) Ready(ctx context.Context, req *readyProto.ReadyRequest) (*ready
 	if req.Service != "" && req.Service != GRPCReadyName {
 		return nil, status.Error(codes.NotFound, "unknown service: "+req.Service+" expected name is "+GRPCReadyName)
 	}
+	if s.ReadyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.ReadyTimeout)
+		defer cancel()
+	}
+
 	isReady, err := s.ReadyChecker.IsReady(ctx)
 	if !isReady {
 		logging.L.WarnF("GRPC ready check failure: %v", err)
 	}
+	if err != nil && !s.isDetailAllowed(ctx) {
+		err = errReasonWithheld
+	}
 
 	return &readyProto.ReadyResponse{Status: isReady}, err
 }
@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/breathbath/healthReadyChecks/auth"
+	"github.com/breathbath/healthReadyChecks/health"
 	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 )
 
 type healthCheckerMock struct {
@@ -27,6 +31,16 @@ func (hcm *healthCheckerMock) SubscribeToUnhealthyChange(sf func(reason string))
 	hcm.subscrF = sf
 }
 
+type statusHealthCheckerMock struct {
+	healthCheckerMock
+	status health.Status
+}
+
+// Status health.StatusProvider implementation
+func (shcm statusHealthCheckerMock) Status() health.Status {
+	return shcm.status
+}
+
 type readyCheckerMock struct {
 	isReady bool
 	err     error
@@ -199,3 +213,80 @@ func TestWatch(t *testing.T) {
 	healthRespFromChecker := watchSrv.resps[0]
 	assert.Equal(t, healthProto.HealthCheckResponse_NOT_SERVING, healthRespFromChecker.Status)
 }
+
+type deadlineReadyChecker struct {
+	deadline    time.Time
+	hasDeadline bool
+}
+
+// IsReady ready.Checker implementation
+func (drc *deadlineReadyChecker) IsReady(ctx context.Context) (isReady bool, err error) {
+	drc.deadline, drc.hasDeadline = ctx.Deadline()
+
+	return true, nil
+}
+
+func TestReadyTimeout(t *testing.T) {
+	rc := &deadlineReadyChecker{}
+	s := Server{ReadyChecker: rc}
+
+	_, err := s.Ready(context.Background(), &readyProto.ReadyRequest{})
+	assert.NoError(t, err)
+	assert.False(t, rc.hasDeadline)
+
+	s.ReadyTimeout = time.Second * 5
+	start := time.Now()
+	_, err = s.Ready(context.Background(), &readyProto.ReadyRequest{})
+	assert.NoError(t, err)
+	assert.True(t, rc.hasDeadline)
+	assert.WithinDuration(t, start.Add(time.Second*5), rc.deadline, time.Millisecond*100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = s.Ready(ctx, &readyProto.ReadyRequest{})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, start.Add(time.Second), rc.deadline, time.Millisecond*100, "the earlier deadline of the caller should be used")
+}
+
+func TestReadyDeadlineOfClient(t *testing.T) {
+	rc := &deadlineReadyChecker{}
+	conn := startBufconnGRPC(t, Server{ReadyChecker: rc, ReadyTimeout: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+	start := time.Now()
+	_, err := readyProto.NewReadyClient(conn).Ready(ctx, &readyProto.ReadyRequest{})
+	assert.NoError(t, err)
+	assert.True(t, rc.hasDeadline)
+	assert.WithinDuration(t, start.Add(time.Second*2), rc.deadline, time.Millisecond*200)
+}
+
+func TestDetailsAuth(t *testing.T) {
+	srv := Server{
+		HealthChecker: &statusHealthCheckerMock{
+			healthCheckerMock: healthCheckerMock{isHealthy: false},
+			status:            health.Status{State: health.StateUnhealthy, ErrorCount: 3, Threshold: 2},
+		},
+		ReadyChecker: readyCheckerMock{isReady: false, err: errors.New("db.internal:5432 is down")},
+		DetailsAuth:  auth.BearerToken("t1"),
+	}
+	conn := startBufconnGRPC(t, srv)
+
+	authorizedCtx := metadata.AppendToOutgoingContext(context.Background(), auth.AuthorizationKey, "Bearer t1")
+
+	_, err := readyProto.NewReadyClient(conn).Ready(authorizedCtx, &readyProto.ReadyRequest{})
+	assert.EqualError(t, err, "rpc error: code = Unknown desc = db.internal:5432 is down")
+
+	_, err = readyProto.NewReadyClient(conn).Ready(context.Background(), &readyProto.ReadyRequest{})
+	assert.EqualError(t, err, "rpc error: code = Unavailable desc = reason withheld")
+
+	md := metadata.MD{}
+	_, err = healthProto.NewHealthClient(conn).Check(authorizedCtx, &healthProto.HealthCheckRequest{}, grpc.Header(&md))
+	assert.NoError(t, err)
+	assert.Len(t, md.Get(HealthStatusHeader), 1)
+
+	md = metadata.MD{}
+	_, err = healthProto.NewHealthClient(conn).Check(context.Background(), &healthProto.HealthCheckRequest{}, grpc.Header(&md))
+	assert.NoError(t, err)
+	assert.Len(t, md.Get(HealthStatusHeader), 0)
+}
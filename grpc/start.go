@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// DefaultGracePeriod how long in-progress calls are awaited on stop by default, see WithGracePeriod
+const DefaultGracePeriod = time.Second * 10
+
+type startConfig struct {
+	serverOpts       []grpc.ServerOption
+	isWithReflection bool
+	started          chan<- net.Addr
+	gracePeriod      time.Duration
+}
+
+// StartOption configures the GRPC server of Server.Start and Server.Serve
+type StartOption func(sc *startConfig)
+
+// WithServerOptions gives the options to grpc.NewServer, e.g. interceptors of NewErrorsUnaryInterceptor
+func WithServerOptions(opts ...grpc.ServerOption) StartOption {
+	return func(sc *startConfig) {
+		sc.serverOpts = append(sc.serverOpts, opts...)
+	}
+}
+
+// WithTLS serves GRPC over TLS with the config, e.g. with tls.Config.ClientAuth for mutual TLS
+func WithTLS(cfg *tls.Config) StartOption {
+	return func(sc *startConfig) {
+		sc.serverOpts = append(sc.serverOpts, grpc.Creds(credentials.NewTLS(cfg)))
+	}
+}
+
+// WithReflection registers the GRPC server reflection service, e.g. for grpcurl
+func WithReflection() StartOption {
+	return func(sc *startConfig) {
+		sc.isWithReflection = true
+	}
+}
+
+// WithStarted sends the address the server listens on to the channel once it is listening,
+// e.g. to know the port chosen for port 0, the channel is not closed
+func WithStarted(started chan<- net.Addr) StartOption {
+	return func(sc *startConfig) {
+		sc.started = started
+	}
+}
+
+// WithGracePeriod limits how long in-progress calls are awaited after the context is done before they are cancelled, 10s by default
+func WithGracePeriod(gracePeriod time.Duration) StartOption {
+	return func(sc *startConfig) {
+		sc.gracePeriod = gracePeriod
+	}
+}
+
+// Register registers health and ready services of the server at the baseSrv
+func (s Server) Register(baseSrv *grpc.Server) {
+	healthProto.RegisterHealthServer(baseSrv, s)
+	readyProto.RegisterReadyServer(baseSrv, s)
+}
+
+// Start listens on the address, e.g. ":9090" or "127.0.0.1:9090", and serves health and ready services like Serve
+func (s Server) Start(ctx context.Context, addr string, opts ...StartOption) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(ctx, lis, opts...)
+}
+
+// Serve serves health and ready services on the listener until the context is done, then stops gracefully and returns nil
+func (s Server) Serve(ctx context.Context, lis net.Listener, opts ...StartOption) error {
+	if s.HealthChecker == nil || s.ReadyChecker == nil {
+		lis.Close()
+		return errors.New("both health and ready checkers are required")
+	}
+
+	sc := &startConfig{gracePeriod: DefaultGracePeriod}
+	for _, opt := range opts {
+		opt(sc)
+	}
+
+	baseSrv := grpc.NewServer(sc.serverOpts...)
+	s.Register(baseSrv)
+	if sc.isWithReflection {
+		reflection.Register(baseSrv)
+	}
+
+	addr := lis.Addr()
+	logging.L.InfoF("Starting health/ready GRPC server at %s", addr)
+
+	serveDone := make(chan struct{})
+	defer close(serveDone)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-serveDone:
+			return
+		}
+		logging.L.InfoF("Exiting health GRPC server at %s", addr)
+		stopGracefully(baseSrv, sc.gracePeriod)
+	}()
+
+	if sc.started != nil {
+		go func() {
+			select {
+			case sc.started <- addr:
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return baseSrv.Serve(lis)
+}
+
+// stopGracefully waits for in-progress calls until the grace period is over, then cancels them
+func stopGracefully(baseSrv *grpc.Server, gracePeriod time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		baseSrv.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+	case <-timer.C:
+		logging.L.WarnF("GRPC calls didn't complete in %v, will cancel them", gracePeriod)
+		baseSrv.Stop()
+	}
+}
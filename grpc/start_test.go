@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionProto "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+func startServer(t *testing.T, srv Server, opts ...StartOption) (addr string, stop func()) {
+	started := make(chan net.Addr, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- srv.Start(ctx, "127.0.0.1:0", append(opts, WithStarted(started))...)
+	}()
+
+	select {
+	case a := <-started:
+		addr = a.String()
+	case err := <-startErr:
+		cancel()
+		assert.FailNow(t, "server is not started", "%v", err)
+	}
+
+	return addr, func() {
+		cancel()
+		assert.NoError(t, <-startErr)
+	}
+}
+
+func TestStart(t *testing.T) {
+	srv := Server{
+		HealthChecker: &healthCheckerMock{isHealthy: true},
+		ReadyChecker:  readyCheckerMock{isReady: true},
+	}
+	addr, stop := startServer(t, srv, WithReflection())
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	healthResp, err := healthProto.NewHealthClient(conn).Check(context.Background(), &healthProto.HealthCheckRequest{})
+	assert.NoError(t, err)
+	if err == nil {
+		assert.Equal(t, healthProto.HealthCheckResponse_SERVING, healthResp.Status)
+	}
+
+	readyResp, err := readyProto.NewReadyClient(conn).Ready(context.Background(), &readyProto.ReadyRequest{})
+	assert.NoError(t, err)
+	if err == nil {
+		assert.True(t, readyResp.Status)
+	}
+
+	stream, err := reflectionProto.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	err = stream.Send(&reflectionProto.ServerReflectionRequest{
+		MessageRequest: &reflectionProto.ServerReflectionRequest_ListServices{},
+	})
+	assert.NoError(t, err)
+	reflectionResp, err := stream.Recv()
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	services := []string{}
+	for _, s := range reflectionResp.GetListServicesResponse().GetService() {
+		services = append(services, s.Name)
+	}
+	assert.ElementsMatch(t, []string{"grpc.health.v1.Health", "readyProto.Ready", "grpc.reflection.v1alpha.ServerReflection"}, services)
+}
+
+func TestStartWithTLS(t *testing.T) {
+	cert, pool := newSelfSignedCert(t)
+
+	srv := Server{
+		HealthChecker: &healthCheckerMock{isHealthy: true},
+		ReadyChecker:  readyCheckerMock{isReady: true},
+	}
+	addr, stop := startServer(t, srv, WithTLS(&tls.Config{Certificates: []tls.Certificate{cert}}))
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	insecureConn, err := grpc.Dial(addr, grpc.WithInsecure())
+	assert.NoError(t, err)
+	if err == nil {
+		_, err = healthProto.NewHealthClient(insecureConn).Check(ctx, &healthProto.HealthCheckRequest{})
+		assert.Error(t, err)
+		insecureConn.Close()
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool, ServerName: "127.0.0.1"})))
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	healthResp, err := healthProto.NewHealthClient(conn).Check(ctx, &healthProto.HealthCheckRequest{})
+	assert.NoError(t, err)
+	if err == nil {
+		assert.Equal(t, healthProto.HealthCheckResponse_SERVING, healthResp.Status)
+	}
+}
+
+func TestServeWithoutCheckers(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	err = Server{HealthChecker: &healthCheckerMock{}}.Serve(context.Background(), lis)
+	assert.EqualError(t, err, "both health and ready checkers are required")
+}
+
+func newSelfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
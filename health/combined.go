@@ -0,0 +1,82 @@
+package health
+
+import "strings"
+
+type combinedChecker struct {
+	checkers []Checker
+}
+
+// Combine gives a Checker which is healthy only if all checkers are healthy, e.g. to join ErrsListener and RuntimeChecker,
+// unhealthy reasons are joined with a comma
+func Combine(checkers ...Checker) Checker {
+	return combinedChecker{checkers: checkers}
+}
+
+// IsHealthy Checker implementation
+func (cc combinedChecker) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	reasons := make([]string, 0, len(cc.checkers))
+	for _, c := range cc.checkers {
+		isHealthy, reason := c.IsHealthy()
+		if !isHealthy {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	return len(reasons) == 0, strings.Join(reasons, ", ")
+}
+
+// SubscribeToUnhealthyChange subscribes the callback to all combined checkers
+func (cc combinedChecker) SubscribeToUnhealthyChange(sf func(reason string)) {
+	for _, c := range cc.checkers {
+		c.SubscribeToUnhealthyChange(sf)
+	}
+}
+
+// Component a named part of the service health, e.g. "storage" or "heartbeats"
+type Component struct {
+	Name    string
+	Checker Checker
+}
+
+// ComponentsProvider is implemented by checkers which consist of named components, e.g. to report every component separately
+type ComponentsProvider interface {
+	Components() []Component
+}
+
+type componentsChecker struct {
+	components []Component
+}
+
+// CombineComponents gives a Checker like Combine which implements ComponentsProvider,
+// unhealthy reasons are prefixed with names of the components
+func CombineComponents(components ...Component) Checker {
+	return componentsChecker{components: components}
+}
+
+// IsHealthy Checker implementation
+func (cc componentsChecker) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	reasons := make([]string, 0, len(cc.components))
+	for _, c := range cc.components {
+		isHealthy, reason := c.Checker.IsHealthy()
+		if !isHealthy {
+			reasons = append(reasons, c.Name+": "+reason)
+		}
+	}
+
+	return len(reasons) == 0, strings.Join(reasons, ", ")
+}
+
+// SubscribeToUnhealthyChange subscribes the callback to all components
+func (cc componentsChecker) SubscribeToUnhealthyChange(sf func(reason string)) {
+	for _, c := range cc.components {
+		c.Checker.SubscribeToUnhealthyChange(sf)
+	}
+}
+
+// Components ComponentsProvider implementation
+func (cc componentsChecker) Components() []Component {
+	components := make([]Component, len(cc.components))
+	copy(components, cc.components)
+
+	return components
+}
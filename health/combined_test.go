@@ -0,0 +1,36 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type staticChecker struct {
+	isHealthy bool
+	reason    string
+}
+
+func (sc staticChecker) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	return sc.isHealthy, sc.reason
+}
+
+func (sc staticChecker) SubscribeToUnhealthyChange(sf func(reason string)) {}
+
+func TestCombineComponents(t *testing.T) {
+	checker := CombineComponents(
+		Component{Name: "storage", Checker: staticChecker{isHealthy: false, reason: "disk is full"}},
+		Component{Name: "heartbeats", Checker: staticChecker{isHealthy: true}},
+		Component{Name: "runtime", Checker: staticChecker{isHealthy: false, reason: "too many goroutines"}},
+	)
+
+	isHealthy, reason := checker.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Equal(t, "storage: disk is full, runtime: too many goroutines", reason)
+
+	cp, ok := checker.(ComponentsProvider)
+	assert.True(t, ok)
+	components := cp.Components()
+	assert.Len(t, components, 3)
+	assert.Equal(t, "heartbeats", components[1].Name)
+}
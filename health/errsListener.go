@@ -20,11 +20,18 @@ type ErrsListener struct {
 	firstErrorTimestamp         int64
 	currentErrorsCountPerMinute int
 	timeUnit                    time.Duration
+	fingerprinter               Fingerprinter
+	countMode                   CountMode
+	topErrorsCount              int
+	fingerprints                map[string]int
+	samplesCount                int
+	samples                     []ErrorSample
+	stateSince                  time.Time
 }
 
 // NewErrsListener constructor for ErrsListener
-func NewErrsListener(maxErrsPerTime int, timeUnit time.Duration, errChan errs.ErrStream) *ErrsListener {
-	return &ErrsListener{
+func NewErrsListener(maxErrsPerTime int, timeUnit time.Duration, errChan errs.ErrStream, opts ...ListenerOption) *ErrsListener {
+	l := &ErrsListener{
 		errs:                        errChan,
 		unhealthyReason:             "",
 		subsrFunc:                   nil,
@@ -33,7 +40,19 @@ func NewErrsListener(maxErrsPerTime int, timeUnit time.Duration, errChan errs.Er
 		firstErrorTimestamp:         0,
 		currentErrorsCountPerMinute: 0,
 		timeUnit:                    timeUnit,
+		countMode:                   CountEvery,
+		topErrorsCount:              defaultTopErrorsCount,
+		fingerprints:                map[string]int{},
+		samplesCount:                defaultSamplesCount,
+		samples:                     []ErrorSample{},
+		stateSince:                  time.Now().UTC(),
 	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
 }
 
 // Start starts listening
@@ -44,7 +63,10 @@ func (l *ErrsListener) Start(ctx context.Context) {
 	logging.L.DebugF("Starting health listener")
 	for {
 		select {
-		case errPayload := <-l.errs:
+		case errPayload, ok := <-l.errs:
+			if !ok {
+				return
+			}
 			l.processErrorPayload(errPayload)
 		case <-ctx.Done():
 			return
@@ -57,33 +79,108 @@ func (l *ErrsListener) processErrorPayload(errPayload errs.ErrPayload) {
 		return
 	}
 
-	logging.L.WarnF("Health check registered an error '%v', will evaluate health toleration", errPayload.Err)
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	if !l.isTooManyErrors() {
-		logging.L.DebugF("The amount of errors %d in the last minute is the acceptable %d", l.currentErrorsCountPerMinute, l.maxErrsPerTime)
+	fingerprint := ""
+	isRepeated := false
+	if l.fingerprinter != nil {
+		fingerprint = l.fingerprinter(errPayload.Err)
+		_, isRepeated = l.fingerprints[fingerprint]
+	}
+
+	switch {
+	case isRepeated:
+		logging.L.DebugF("Health check registered a repeated error '%v', will evaluate health toleration", errPayload.Err)
+	case errPayload.Weight() > 1:
+		logging.L.WarnF("Health check registered %d errors '%v', will evaluate health toleration", errPayload.Weight(), errPayload.Err)
+	default:
+		logging.L.WarnF("Health check registered an error '%v', will evaluate health toleration", errPayload.Err)
+	}
+
+	l.addSample(errPayload)
+
+	if !l.isTooManyErrors(errPayload.Weight(), fingerprint) {
+		logging.L.DebugF("The amount of errors %d in the last minute is the acceptable %d", l.errorsCount(), l.maxErrsPerTime)
 		return
 	}
-	logging.L.WarnF("The amount of critical errors %d in the last minute is not the acceptable %d, will report health failure", l.currentErrorsCountPerMinute, l.maxErrsPerTime)
-	l.unhealthyReason = fmt.Sprintf("Too many critical errors %d in the last minute %d, last error: %v", l.currentErrorsCountPerMinute, l.firstErrorTimestamp, errPayload.Err)
+	logging.L.WarnF("The amount of critical errors %d in the last minute is not the acceptable %d, will report health failure", l.errorsCount(), l.maxErrsPerTime)
+	if l.unhealthyReason == "" {
+		l.stateSince = time.Now().UTC()
+	}
+	l.unhealthyReason = fmt.Sprintf("Too many critical errors %d in the last minute %d, last error: %v", l.errorsCount(), l.firstErrorTimestamp, errPayload.Err)
+	if l.fingerprinter != nil {
+		l.unhealthyReason += ", top errors: " + formatTopErrors(l.fingerprints, l.topErrorsCount)
+	}
 	if l.subsrFunc != nil {
 		l.subsrFunc(l.unhealthyReason)
 	}
 }
 
-func (l *ErrsListener) isTooManyErrors() bool {
-	l.currentErrorsCountPerMinute++
+func (l *ErrsListener) isTooManyErrors(weight int, fingerprint string) bool {
+	l.currentErrorsCountPerMinute += weight
+	if l.fingerprinter != nil {
+		l.fingerprints[fingerprint] += weight
+	}
 
 	nowTimestamp := time.Now().UTC().Unix()
 	secondsAmountToCheck := l.timeUnit / time.Second
-	if l.currentErrorsCountPerMinute == 0 || (nowTimestamp-l.firstErrorTimestamp > int64(secondsAmountToCheck) && l.currentErrorsCountPerMinute <= l.maxErrsPerTime) {
-		l.currentErrorsCountPerMinute = 1
+	if nowTimestamp-l.firstErrorTimestamp > int64(secondsAmountToCheck) && l.errorsCount() <= l.maxErrsPerTime {
+		l.currentErrorsCountPerMinute = weight
 		l.firstErrorTimestamp = nowTimestamp
+		if l.fingerprinter != nil {
+			l.fingerprints = map[string]int{fingerprint: weight}
+		}
 		return false
 	}
 
-	return l.currentErrorsCountPerMinute > l.maxErrsPerTime
+	return l.errorsCount() > l.maxErrsPerTime
+}
+
+func (l *ErrsListener) addSample(errPayload errs.ErrPayload) {
+	if l.samplesCount <= 0 {
+		return
+	}
+
+	l.samples = append(l.samples, newErrorSample(errPayload))
+	if len(l.samples) > l.samplesCount {
+		l.samples = l.samples[len(l.samples)-l.samplesCount:]
+	}
+}
+
+// errorsCount amount of errors in the current window according to the count mode
+func (l *ErrsListener) errorsCount() int {
+	if l.countMode == CountDistinct {
+		return len(l.fingerprints)
+	}
+
+	return l.currentErrorsCountPerMinute
+}
+
+// Reconfigure atomically replaces the errors threshold and the time unit, errors which are processed right now are evaluated
+// against the old values, the current errors window is kept
+func (l *ErrsListener) Reconfigure(maxErrsPerTime int, timeUnit time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	logging.L.InfoF("Health listener is reconfigured to %d errors per %v", maxErrsPerTime, timeUnit)
+	l.maxErrsPerTime = maxErrsPerTime
+	l.timeUnit = timeUnit
+}
+
+// Reset forgets errors of the current window and reports health again, e.g. after an incident is resolved, samples are kept
+func (l *ErrsListener) Reset() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	logging.L.InfoF("Health listener is reset")
+	if l.unhealthyReason != "" {
+		l.stateSince = time.Now().UTC()
+	}
+	l.unhealthyReason = ""
+	l.firstErrorTimestamp = 0
+	l.currentErrorsCountPerMinute = 0
+	l.fingerprints = map[string]int{}
 }
 
 // IsHealthy returns health check result
@@ -94,6 +191,32 @@ func (l *ErrsListener) IsHealthy() (isHealthy bool, unhealthyReason string) {
 	return l.unhealthyReason == "", l.unhealthyReason
 }
 
+// Status gives the detailed health with the current errors window and the last error samples, oldest first
+func (l *ErrsListener) Status() Status {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	st := Status{
+		State:      StateHealthy,
+		Since:      l.stateSince,
+		Reason:     l.unhealthyReason,
+		ErrorCount: l.errorsCount(),
+		Threshold:  l.maxErrsPerTime,
+		Samples:    make([]ErrorSample, len(l.samples)),
+	}
+	copy(st.Samples, l.samples)
+
+	if l.unhealthyReason != "" {
+		st.State = StateUnhealthy
+	}
+	if l.firstErrorTimestamp > 0 {
+		st.WindowStart = time.Unix(l.firstErrorTimestamp, 0).UTC()
+		st.WindowEnd = st.WindowStart.Add(l.timeUnit)
+	}
+
+	return st
+}
+
 // SubscribeToUnhealthyChange accepts the callback which will be executed on unhealthy status change
 func (l *ErrsListener) SubscribeToUnhealthyChange(sf func(reason string)) {
 	l.lock.Lock()
@@ -93,3 +93,114 @@ func consumeErrStream(ctx context.Context, sourceStream chan string) []string {
 		}
 	}
 }
+
+func TestReconfigure(t *testing.T) {
+	errStream := errs.NewErrStream(0)
+	l := NewErrsListener(1, time.Minute, errStream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	go l.Start(ctx)
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			l.Reconfigure(3+i, time.Minute)
+		}
+		done <- true
+	}()
+
+	errStream.Send(errors.New("some err1"))
+	errStream.Send(errors.New("some err2"))
+	<-done
+	l.Reconfigure(3, time.Minute)
+	errStream.Send(errors.New("some err3"))
+	// nil errors are ignored, the send returns once the previous error is processed
+	errStream.Send(nil)
+
+	isHealthy, _ := l.IsHealthy()
+	assert.True(t, isHealthy)
+
+	errStream.Send(errors.New("some err4"))
+
+	<-ctx.Done()
+
+	isHealthy, unhealthyReason := l.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, unhealthyReason, "Too many critical errors 4")
+}
+
+func TestWeightedErrors(t *testing.T) {
+	errStream := errs.NewErrStream(0)
+	l := NewErrsListener(3, time.Minute, errStream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	go l.Start(ctx)
+
+	errStream <- errs.ErrPayload{Err: errors.New("conn refused"), Timestamp: time.Now().Unix(), Count: 3}
+
+	isHealthy, _ := l.IsHealthy()
+	assert.True(t, isHealthy)
+
+	errStream <- errs.ErrPayload{Err: errs.ErrOverflow, Timestamp: time.Now().Unix(), Count: 2}
+
+	<-ctx.Done()
+
+	isHealthy, unhealthyReason := l.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, unhealthyReason, "Too many critical errors 5")
+}
+
+func TestClosedStreamStopsListener(t *testing.T) {
+	errStream := errs.NewErrStream(0)
+	l := NewErrsListener(1, time.Minute, errStream)
+
+	done := make(chan bool)
+	go func() {
+		l.Start(context.Background())
+		done <- true
+	}()
+
+	close(errStream)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Listener didn't stop on a closed stream")
+	}
+}
+
+func TestReset(t *testing.T) {
+	errStream := errs.NewErrStream(0)
+	l := NewErrsListener(1, time.Minute, errStream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Start(ctx)
+
+	errStream.Send(errors.New("some err1"))
+	errStream.Send(errors.New("some err2"))
+	errStream.Send(errors.New("some err3"))
+
+	isHealthy, _ := l.IsHealthy()
+	assert.False(t, isHealthy)
+
+	l.Reset()
+
+	isHealthy, unhealthyReason := l.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", unhealthyReason)
+
+	st := l.Status()
+	assert.Equal(t, StateHealthy, st.State)
+	assert.Equal(t, 0, st.ErrorCount)
+	assert.Len(t, st.Samples, 3)
+
+	errStream.Send(errors.New("some err4"))
+	errStream.Send(nil)
+	isHealthy, _ = l.IsHealthy()
+	assert.True(t, isHealthy)
+}
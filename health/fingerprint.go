@@ -0,0 +1,122 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Fingerprinter gives a key which is identical for errors of the same kind, e.g. for thousands of "connection refused" errors of a broken downstream
+type Fingerprinter func(err error) string
+
+// CountMode tells ErrsListener how errors are counted against the threshold
+type CountMode int
+
+const (
+	// CountEvery counts every error occurrence
+	CountEvery CountMode = iota
+	// CountDistinct counts distinct error fingerprints per time unit, so a single broken downstream counts only once
+	CountDistinct
+)
+
+const defaultTopErrorsCount = 3
+
+var (
+	uuidRegex   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	hexRegex    = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	numberRegex = regexp.MustCompile(`\d+`)
+)
+
+// FingerprintByMessage normalizes the error message by replacing uuids, hex and decimal numbers (including ip addresses and ports)
+// with placeholders, e.g. "dial tcp 10.0.0.1:5432: connection refused" gives "dial tcp #.#.#.#:#: connection refused"
+func FingerprintByMessage(err error) string {
+	msg := uuidRegex.ReplaceAllString(err.Error(), "<uuid>")
+	msg = hexRegex.ReplaceAllString(msg, "<hex>")
+
+	return numberRegex.ReplaceAllString(msg, "#")
+}
+
+// FingerprintByType gives the type of the innermost wrapped error, e.g. *net.OpError
+func FingerprintByType(err error) string {
+	for {
+		unwrapped := errors.Unwrap(err)
+		if unwrapped == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = unwrapped
+	}
+}
+
+// FingerprintBySentinel gives the message of the first sentinel which matches the error with errors.Is,
+// other errors are fingerprinted with FingerprintByMessage
+func FingerprintBySentinel(sentinels ...error) Fingerprinter {
+	return func(err error) string {
+		for _, s := range sentinels {
+			if errors.Is(err, s) {
+				return s.Error()
+			}
+		}
+
+		return FingerprintByMessage(err)
+	}
+}
+
+// ListenerOption configures optional ErrsListener behavior
+type ListenerOption func(l *ErrsListener)
+
+// WithFingerprinter groups errors by the fingerprint, repeated errors within the time unit are logged only once
+// and the unhealthy reason contains the most frequent errors
+func WithFingerprinter(f Fingerprinter) ListenerOption {
+	return func(l *ErrsListener) {
+		l.fingerprinter = f
+	}
+}
+
+// WithCountMode sets how errors are counted against the threshold, CountDistinct uses FingerprintByMessage if no fingerprinter is given
+func WithCountMode(mode CountMode) ListenerOption {
+	return func(l *ErrsListener) {
+		l.countMode = mode
+		if mode == CountDistinct && l.fingerprinter == nil {
+			l.fingerprinter = FingerprintByMessage
+		}
+	}
+}
+
+// WithTopErrorsCount sets amount of the most frequent errors in the unhealthy reason, defaults to 3
+func WithTopErrorsCount(count int) ListenerOption {
+	return func(l *ErrsListener) {
+		l.topErrorsCount = count
+	}
+}
+
+type fingerprintStats struct {
+	fingerprint string
+	count       int
+}
+
+func formatTopErrors(fingerprints map[string]int, limit int) string {
+	stats := make([]fingerprintStats, 0, len(fingerprints))
+	for fp, count := range fingerprints {
+		stats = append(stats, fingerprintStats{fingerprint: fp, count: count})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].count == stats[j].count {
+			return stats[i].fingerprint < stats[j].fingerprint
+		}
+		return stats[i].count > stats[j].count
+	})
+
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+
+	items := make([]string, 0, len(stats))
+	for _, s := range stats {
+		items = append(items, fmt.Sprintf("%s (x%d)", s.fingerprint, s.count))
+	}
+
+	return strings.Join(items, "; ")
+}
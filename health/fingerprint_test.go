@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+var errConnRefused = errors.New("connection refused")
+
+func TestFingerprintByMessage(t *testing.T) {
+	assert.Equal(
+		t,
+		"dial tcp #.#.#.#:#: connection refused",
+		FingerprintByMessage(errors.New("dial tcp 10.0.0.1:5432: connection refused")),
+	)
+	assert.Equal(
+		t,
+		"order <uuid> failed at <hex>",
+		FingerprintByMessage(errors.New("order 123e4567-e89b-12d3-a456-426614174000 failed at 0x1f2e")),
+	)
+}
+
+func TestFingerprintByType(t *testing.T) {
+	opErr := &net.OpError{Op: "dial", Err: errConnRefused}
+	assert.Equal(t, "*errors.errorString", FingerprintByType(fmt.Errorf("wrapped: %w", opErr)))
+	assert.Equal(t, "*net.DNSError", FingerprintByType(fmt.Errorf("wrapped: %w", &net.DNSError{Name: "db"})))
+}
+
+func TestFingerprintBySentinel(t *testing.T) {
+	f := FingerprintBySentinel(errConnRefused)
+	assert.Equal(t, "connection refused", f(fmt.Errorf("dial 10.0.0.1: %w", errConnRefused)))
+	assert.Equal(t, "timeout after #ms", f(errors.New("timeout after 100ms")))
+}
+
+func TestFormatTopErrors(t *testing.T) {
+	assert.Equal(
+		t,
+		"conn refused (x5); a (x2); b (x2)",
+		formatTopErrors(map[string]int{"b": 2, "conn refused": 5, "a": 2, "c": 1}, 3),
+	)
+}
+
+func TestCountDistinctFingerprints(t *testing.T) {
+	errStream := errs.NewErrStream(0)
+	l := NewErrsListener(2, time.Minute, errStream, WithCountMode(CountDistinct), WithTopErrorsCount(2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	go l.Start(ctx)
+
+	for i := 0; i < 100; i++ {
+		errStream.Send(fmt.Errorf("dial tcp 10.0.0.%d:5432: connection refused", i))
+	}
+	errStream.Send(errors.New("disk full"))
+
+	isHealthy, _ := l.IsHealthy()
+	assert.True(t, isHealthy)
+
+	errStream.Send(errors.New("disk full"))
+	errStream.Send(errors.New("out of memory"))
+
+	<-ctx.Done()
+
+	isHealthy, unhealthyReason := l.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Equal(
+		t,
+		fmt.Sprintf(
+			"Too many critical errors 3 in the last minute %d, last error: out of memory, top errors: dial tcp #.#.#.#:#: connection refused (x100); disk full (x2)",
+			l.firstErrorTimestamp,
+		),
+		unhealthyReason,
+	)
+}
+
+func TestCountEveryWithFingerprinter(t *testing.T) {
+	errStream := errs.NewErrStream(0)
+	l := NewErrsListener(2, time.Minute, errStream, WithFingerprinter(FingerprintBySentinel(errConnRefused)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	go l.Start(ctx)
+
+	errStream.Send(fmt.Errorf("dial db: %w", errConnRefused))
+	errStream.Send(fmt.Errorf("dial cache: %w", errConnRefused))
+	errStream.Send(errors.New("disk full"))
+
+	<-ctx.Done()
+
+	isHealthy, unhealthyReason := l.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Equal(
+		t,
+		fmt.Sprintf(
+			"Too many critical errors 3 in the last minute %d, last error: disk full, top errors: connection refused (x2); disk full (x1)",
+			l.firstErrorTimestamp,
+		),
+		unhealthyReason,
+	)
+}
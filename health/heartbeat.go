@@ -0,0 +1,155 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+// Heartbeat is beaten by an application loop to show that it's not stalled
+type Heartbeat struct {
+	name     string
+	interval time.Duration
+	lock     sync.Mutex
+	lastBeat time.Time
+}
+
+func newHeartbeat(name string, interval time.Duration) *Heartbeat {
+	return &Heartbeat{
+		name:     name,
+		interval: interval,
+		lock:     sync.Mutex{},
+		lastBeat: time.Now().UTC(),
+	}
+}
+
+// Beat marks the loop as alive, it should be called at least once per the registered interval
+func (h *Heartbeat) Beat() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.lastBeat = time.Now().UTC()
+}
+
+// overdue gives the time passed since the last beat and true if it's longer than the interval with the tolerance
+func (h *Heartbeat) overdue(tolerance time.Duration) (sinceBeat time.Duration, isOverdue bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	sinceBeat = time.Since(h.lastBeat)
+
+	return sinceBeat, sinceBeat > h.interval+tolerance
+}
+
+// HeartbeatMonitor implements health checks based on heartbeats of application loops, it detects hung consumers
+// which produce no errors, the health recovers as soon as all beats resume
+type HeartbeatMonitor struct {
+	tolerance       time.Duration
+	lock            sync.Mutex
+	heartbeats      []*Heartbeat
+	unhealthyReason string
+	subsrFunc       func(reason string)
+}
+
+// NewHeartbeatMonitor constructor for HeartbeatMonitor, a heartbeat is overdue if it's not beaten within its interval plus the tolerance
+func NewHeartbeatMonitor(tolerance time.Duration) *HeartbeatMonitor {
+	return &HeartbeatMonitor{
+		tolerance:  tolerance,
+		lock:       sync.Mutex{},
+		heartbeats: []*Heartbeat{},
+	}
+}
+
+// Register adds a named heartbeat which is expected to be beaten every interval, the interval starts with the registration,
+// registering an existing name replaces the heartbeat
+func (m *HeartbeatMonitor) Register(name string, interval time.Duration) *Heartbeat {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	hb := newHeartbeat(name, interval)
+	for i, existing := range m.heartbeats {
+		if existing.name == name {
+			m.heartbeats[i] = hb
+			return hb
+		}
+	}
+	m.heartbeats = append(m.heartbeats, hb)
+
+	return hb
+}
+
+// Unregister stops monitoring of the named heartbeat, e.g. when its loop is finished
+func (m *HeartbeatMonitor) Unregister(name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for i, existing := range m.heartbeats {
+		if existing.name == name {
+			m.heartbeats = append(m.heartbeats[:i], m.heartbeats[i+1:]...)
+			return
+		}
+	}
+}
+
+// Start checks heartbeats every checkInterval until the context is done, it's needed only to notify the unhealthy change subscriber
+func (m *HeartbeatMonitor) Start(ctx context.Context, checkInterval time.Duration) {
+	defer func() {
+		logging.L.DebugF("Exiting heartbeat monitor")
+	}()
+	logging.L.DebugF("Starting heartbeat monitor")
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.IsHealthy()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// IsHealthy Checker implementation, reports the stalled loops in the reason
+func (m *HeartbeatMonitor) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	m.lock.Lock()
+
+	failures := make([]string, 0, len(m.heartbeats))
+	for _, hb := range m.heartbeats {
+		sinceBeat, isOverdue := hb.overdue(m.tolerance)
+		if isOverdue {
+			failures = append(failures, fmt.Sprintf("Heartbeat %s is stalled for %v, expected every %v", hb.name, sinceBeat.Round(time.Millisecond), hb.interval))
+		}
+	}
+
+	wasHealthy := m.unhealthyReason == ""
+	m.unhealthyReason = strings.Join(failures, ", ")
+	reason := m.unhealthyReason
+	subsrFunc := m.subsrFunc
+	m.lock.Unlock()
+
+	switch {
+	case wasHealthy && reason != "":
+		logging.L.WarnF("Heartbeat health check failed: %s", reason)
+		if subsrFunc != nil {
+			subsrFunc(reason)
+		}
+	case !wasHealthy && reason == "":
+		logging.L.InfoF("Heartbeats are resumed")
+	}
+
+	return reason == "", reason
+}
+
+// SubscribeToUnhealthyChange accepts the callback which will be executed when any heartbeat becomes overdue
+func (m *HeartbeatMonitor) SubscribeToUnhealthyChange(sf func(reason string)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.subsrFunc = sf
+}
@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeatMonitor(t *testing.T) {
+	m := NewHeartbeatMonitor(time.Millisecond * 10)
+	consumer := m.Register("consumer", time.Millisecond*20)
+	producer := m.Register("producer", time.Millisecond*20)
+
+	reasons := []string{}
+	m.SubscribeToUnhealthyChange(func(reason string) {
+		reasons = append(reasons, reason)
+	})
+
+	isHealthy, reason := m.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", reason)
+
+	time.Sleep(time.Millisecond * 20)
+	producer.Beat()
+	consumer.Beat()
+
+	isHealthy, _ = m.IsHealthy()
+	assert.True(t, isHealthy, "beats within interval and tolerance should be healthy")
+
+	time.Sleep(time.Millisecond * 40)
+	producer.Beat()
+
+	isHealthy, reason = m.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, reason, "Heartbeat consumer is stalled for")
+	assert.Contains(t, reason, "expected every 20ms")
+	assert.NotContains(t, reason, "producer")
+
+	m.IsHealthy()
+	assert.Len(t, reasons, 1)
+
+	consumer.Beat()
+	isHealthy, reason = m.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", reason)
+}
+
+func TestHeartbeatMonitorUnregister(t *testing.T) {
+	m := NewHeartbeatMonitor(0)
+	m.Register("worker", time.Millisecond)
+	m.Register("worker", time.Hour)
+
+	time.Sleep(time.Millisecond * 5)
+	isHealthy, _ := m.IsHealthy()
+	assert.True(t, isHealthy, "registering the same name should replace the heartbeat")
+
+	m.Register("batch", time.Millisecond)
+	time.Sleep(time.Millisecond * 5)
+	isHealthy, reason := m.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, reason, "Heartbeat batch is stalled")
+
+	m.Unregister("batch")
+	isHealthy, _ = m.IsHealthy()
+	assert.True(t, isHealthy)
+}
+
+func TestHeartbeatMonitorStart(t *testing.T) {
+	m := NewHeartbeatMonitor(0)
+	m.Register("loop", time.Millisecond*10)
+
+	reasons := make(chan string, 1)
+	m.SubscribeToUnhealthyChange(func(reason string) {
+		reasons <- reason
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Start(ctx, time.Millisecond*5)
+
+	select {
+	case reason := <-reasons:
+		assert.Contains(t, reason, "Heartbeat loop is stalled")
+	case <-time.After(time.Second):
+		assert.Fail(t, "stalled heartbeat was not reported")
+	}
+}
@@ -0,0 +1,165 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+const ratioBucketsCount = 10
+
+// OutcomeRecorder records results of operations, e.g. of handled requests, implemented by RatioListener
+type OutcomeRecorder interface {
+	RecordSuccess()
+	RecordFailure(err error)
+}
+
+// RatioOptions thresholds of RatioListener
+type RatioOptions struct {
+	// MaxFailureRatio max acceptable share of failed operations from 0 to 1, e.g. 0.2 for 20%
+	MaxFailureRatio float64
+	// MinRequests min amount of operations in the window to evaluate the ratio, less operations are always healthy
+	MinRequests int
+	// Window duration of the sliding window of operations, defaults to 1m
+	Window time.Duration
+}
+
+type ratioBucket struct {
+	id        int64
+	successes int
+	failures  int
+}
+
+// RatioListener implements health checks based on the ratio of failed operations to all operations in a sliding window,
+// so the threshold doesn't depend on the load of the service, the health recovers when the ratio drops below the limit
+type RatioListener struct {
+	opts            RatioOptions
+	bucketSize      time.Duration
+	lock            sync.Mutex
+	buckets         [ratioBucketsCount]ratioBucket
+	lastErr         error
+	unhealthyReason string
+	subsrFunc       func(reason string)
+	now             func() time.Time
+}
+
+// NewRatioListener constructor for RatioListener
+func NewRatioListener(opts RatioOptions) *RatioListener {
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+
+	bucketSize := opts.Window / ratioBucketsCount
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+
+	return &RatioListener{
+		opts:       opts,
+		bucketSize: bucketSize,
+		lock:       sync.Mutex{},
+		now:        time.Now,
+	}
+}
+
+// RecordSuccess OutcomeRecorder implementation
+func (rl *RatioListener) RecordSuccess() {
+	rl.record(nil)
+}
+
+// RecordFailure OutcomeRecorder implementation
+func (rl *RatioListener) RecordFailure(err error) {
+	rl.record(err)
+}
+
+func (rl *RatioListener) record(err error) {
+	rl.lock.Lock()
+
+	b := rl.bucket(rl.now())
+	if err == nil {
+		b.successes++
+	} else {
+		b.failures++
+		rl.lastErr = err
+	}
+
+	rl.evaluateAndUnlock()
+}
+
+// bucket gives the bucket of the time, resetting it if it belongs to a previous window
+func (rl *RatioListener) bucket(t time.Time) *ratioBucket {
+	id := t.UnixNano() / int64(rl.bucketSize)
+	b := &rl.buckets[id%ratioBucketsCount]
+	if b.id != id {
+		*b = ratioBucket{id: id}
+	}
+
+	return b
+}
+
+func (rl *RatioListener) totals() (successes, failures int) {
+	currentID := rl.now().UnixNano() / int64(rl.bucketSize)
+	for _, b := range rl.buckets {
+		if b.id > currentID-ratioBucketsCount {
+			successes += b.successes
+			failures += b.failures
+		}
+	}
+
+	return successes, failures
+}
+
+// evaluateAndUnlock updates the health state and notifies the subscriber outside of the lock
+func (rl *RatioListener) evaluateAndUnlock() (isHealthy bool, unhealthyReason string) {
+	successes, failures := rl.totals()
+	total := successes + failures
+
+	wasHealthy := rl.unhealthyReason == ""
+	rl.unhealthyReason = ""
+	if total > 0 && total >= rl.opts.MinRequests {
+		ratio := float64(failures) / float64(total)
+		if ratio > rl.opts.MaxFailureRatio {
+			rl.unhealthyReason = fmt.Sprintf(
+				"Failure ratio %.1f%% of %d operations in the last %v is above the limit %.1f%%, last error: %v",
+				ratio*100,
+				total,
+				rl.opts.Window,
+				rl.opts.MaxFailureRatio*100,
+				rl.lastErr,
+			)
+		}
+	}
+
+	reason := rl.unhealthyReason
+	subsrFunc := rl.subsrFunc
+	rl.lock.Unlock()
+
+	switch {
+	case wasHealthy && reason != "":
+		logging.L.WarnF("Failure ratio health check failed: %s", reason)
+		if subsrFunc != nil {
+			subsrFunc(reason)
+		}
+	case !wasHealthy && reason == "":
+		logging.L.InfoF("Failure ratio is acceptable again")
+	}
+
+	return reason == "", reason
+}
+
+// IsHealthy Checker implementation, evaluates the ratio in the current window
+func (rl *RatioListener) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	rl.lock.Lock()
+
+	return rl.evaluateAndUnlock()
+}
+
+// SubscribeToUnhealthyChange accepts the callback which will be executed when the failure ratio goes above the limit
+func (rl *RatioListener) SubscribeToUnhealthyChange(sf func(reason string)) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	rl.subsrFunc = sf
+}
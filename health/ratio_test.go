@@ -0,0 +1,79 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRatioListener(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := NewRatioListener(RatioOptions{MaxFailureRatio: 0.2, MinRequests: 10, Window: time.Second * 10})
+	rl.now = func() time.Time {
+		return now
+	}
+
+	reasons := []string{}
+	rl.SubscribeToUnhealthyChange(func(reason string) {
+		reasons = append(reasons, reason)
+	})
+
+	for i := 0; i < 5; i++ {
+		rl.RecordFailure(errors.New("some err"))
+	}
+	isHealthy, _ := rl.IsHealthy()
+	assert.True(t, isHealthy, "less operations than MinRequests should be healthy")
+
+	for i := 0; i < 15; i++ {
+		rl.RecordSuccess()
+	}
+	isHealthy, reason := rl.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Equal(t, "Failure ratio 25.0% of 20 operations in the last 10s is above the limit 20.0%, last error: some err", reason)
+	assert.Len(t, reasons, 1)
+	assert.Contains(t, reasons[0], "Failure ratio 50.0% of 10 operations")
+
+	for i := 0; i < 5; i++ {
+		rl.RecordSuccess()
+	}
+	isHealthy, reason = rl.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", reason)
+
+	for i := 0; i < 5; i++ {
+		rl.RecordFailure(errors.New("another err"))
+	}
+	isHealthy, _ = rl.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Len(t, reasons, 2)
+
+	now = now.Add(time.Second * 10)
+	isHealthy, _ = rl.IsHealthy()
+	assert.True(t, isHealthy, "operations outside of the window should be ignored")
+}
+
+func TestRatioListenerSlidingWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := NewRatioListener(RatioOptions{MaxFailureRatio: 0.5, MinRequests: 1, Window: time.Second * 10})
+	rl.now = func() time.Time {
+		return now
+	}
+
+	rl.RecordFailure(errors.New("old err"))
+	now = now.Add(time.Second * 5)
+	rl.RecordSuccess()
+
+	isHealthy, _ := rl.IsHealthy()
+	assert.True(t, isHealthy)
+
+	rl.RecordFailure(errors.New("new err"))
+	isHealthy, reason := rl.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, reason, "Failure ratio 66.7% of 3 operations")
+
+	now = now.Add(time.Second * 6)
+	isHealthy, _ = rl.IsHealthy()
+	assert.True(t, isHealthy, "the oldest failure should slide out of the window")
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+// RuntimeLimits thresholds of Go runtime vitals, zero values disable the corresponding check
+type RuntimeLimits struct {
+	// MaxGoroutines max amount of running goroutines, e.g. to detect goroutine leaks
+	MaxGoroutines int
+	// MaxHeapBytes max amount of allocated heap bytes
+	MaxHeapBytes uint64
+	// MaxGCPause max stop the world pause of a single GC cycle since the previous check
+	MaxGCPause time.Duration
+	// WatchdogTimeout max interval between Tick calls, e.g. from the main event loop of the application
+	WatchdogTimeout time.Duration
+}
+
+type runtimeStats struct {
+	goroutines int
+	heapBytes  uint64
+	numGC      uint32
+	maxGCPause time.Duration
+}
+
+// RuntimeChecker implements health checks based on Go runtime vitals, it can be combined with ErrsListener with Combine
+type RuntimeChecker struct {
+	limits          RuntimeLimits
+	lock            sync.Mutex
+	unhealthyReason string
+	subsrFunc       func(reason string)
+	watchdog        *Heartbeat
+	lastNumGC       uint32
+	readStats       func(sinceNumGC uint32) runtimeStats
+}
+
+// NewRuntimeChecker constructor for RuntimeChecker
+func NewRuntimeChecker(limits RuntimeLimits) *RuntimeChecker {
+	return &RuntimeChecker{
+		limits:    limits,
+		lock:      sync.Mutex{},
+		watchdog:  newHeartbeat("watchdog", limits.WatchdogTimeout),
+		readStats: readRuntimeStats,
+	}
+}
+
+// Tick notifies the watchdog that the application is alive, it should be called more often than WatchdogTimeout
+func (rc *RuntimeChecker) Tick() {
+	rc.watchdog.Beat()
+}
+
+// Start evaluates runtime vitals every checkInterval until the context is done
+func (rc *RuntimeChecker) Start(ctx context.Context, checkInterval time.Duration) {
+	defer func() {
+		logging.L.DebugF("Exiting runtime health checker")
+	}()
+	logging.L.DebugF("Starting runtime health checker")
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rc.check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (rc *RuntimeChecker) check() {
+	rc.lock.Lock()
+
+	stats := rc.readStats(rc.lastNumGC)
+	rc.lastNumGC = stats.numGC
+
+	failures := make([]string, 0, 4)
+	if rc.limits.MaxGoroutines > 0 && stats.goroutines > rc.limits.MaxGoroutines {
+		failures = append(failures, fmt.Sprintf("Too many goroutines %d, the limit is %d", stats.goroutines, rc.limits.MaxGoroutines))
+	}
+	if rc.limits.MaxHeapBytes > 0 && stats.heapBytes > rc.limits.MaxHeapBytes {
+		failures = append(failures, fmt.Sprintf("Heap usage %d bytes is above the limit %d", stats.heapBytes, rc.limits.MaxHeapBytes))
+	}
+	if rc.limits.MaxGCPause > 0 && stats.maxGCPause > rc.limits.MaxGCPause {
+		failures = append(failures, fmt.Sprintf("GC pause %v is above the limit %v", stats.maxGCPause, rc.limits.MaxGCPause))
+	}
+	if rc.limits.WatchdogTimeout > 0 {
+		sinceTick, isOverdue := rc.watchdog.overdue(0)
+		if isOverdue {
+			failures = append(failures, fmt.Sprintf("Watchdog was not ticked for %v, the timeout is %v", sinceTick.Round(time.Millisecond), rc.limits.WatchdogTimeout))
+		}
+	}
+
+	wasHealthy := rc.unhealthyReason == ""
+	rc.unhealthyReason = strings.Join(failures, ", ")
+	reason := rc.unhealthyReason
+	subsrFunc := rc.subsrFunc
+	rc.lock.Unlock()
+
+	switch {
+	case wasHealthy && reason != "":
+		logging.L.WarnF("Runtime health check failed: %s", reason)
+		if subsrFunc != nil {
+			subsrFunc(reason)
+		}
+	case !wasHealthy && reason == "":
+		logging.L.InfoF("Runtime health check recovered")
+	}
+}
+
+// IsHealthy returns the result of the last runtime vitals evaluation
+func (rc *RuntimeChecker) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	return rc.unhealthyReason == "", rc.unhealthyReason
+}
+
+// SubscribeToUnhealthyChange accepts the callback which will be executed when runtime vitals turn to unhealthy
+func (rc *RuntimeChecker) SubscribeToUnhealthyChange(sf func(reason string)) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	rc.subsrFunc = sf
+}
+
+func readRuntimeStats(sinceNumGC uint32) runtimeStats {
+	memStats := runtime.MemStats{}
+	runtime.ReadMemStats(&memStats)
+
+	stats := runtimeStats{
+		goroutines: runtime.NumGoroutine(),
+		heapBytes:  memStats.HeapAlloc,
+		numGC:      memStats.NumGC,
+	}
+
+	// PauseNs is a circular buffer of the last 256 GC pauses
+	newCycles := memStats.NumGC - sinceNumGC
+	if newCycles > uint32(len(memStats.PauseNs)) {
+		newCycles = uint32(len(memStats.PauseNs))
+	}
+	for i := uint32(0); i < newCycles; i++ {
+		pause := time.Duration(memStats.PauseNs[(memStats.NumGC-i+255)%256])
+		if pause > stats.maxGCPause {
+			stats.maxGCPause = pause
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,126 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeChecker(t *testing.T) {
+	stats := runtimeStats{goroutines: 10, heapBytes: 100}
+	rc := NewRuntimeChecker(RuntimeLimits{MaxGoroutines: 20, MaxHeapBytes: 200, MaxGCPause: time.Millisecond})
+	rc.readStats = func(sinceNumGC uint32) runtimeStats {
+		return stats
+	}
+
+	reasons := []string{}
+	rc.SubscribeToUnhealthyChange(func(reason string) {
+		reasons = append(reasons, reason)
+	})
+
+	rc.check()
+	isHealthy, reason := rc.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", reason)
+
+	stats = runtimeStats{goroutines: 30, heapBytes: 300, maxGCPause: time.Second}
+	rc.check()
+	isHealthy, reason = rc.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Equal(
+		t,
+		"Too many goroutines 30, the limit is 20, Heap usage 300 bytes is above the limit 200, GC pause 1s is above the limit 1ms",
+		reason,
+	)
+
+	rc.check()
+	assert.Equal(t, []string{reason}, reasons)
+
+	stats = runtimeStats{goroutines: 10, heapBytes: 100}
+	rc.check()
+	isHealthy, _ = rc.IsHealthy()
+	assert.True(t, isHealthy)
+}
+
+func TestRuntimeCheckerWatchdog(t *testing.T) {
+	rc := NewRuntimeChecker(RuntimeLimits{WatchdogTimeout: time.Millisecond * 20})
+
+	rc.check()
+	isHealthy, _ := rc.IsHealthy()
+	assert.True(t, isHealthy)
+
+	time.Sleep(time.Millisecond * 30)
+	rc.check()
+	isHealthy, reason := rc.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, reason, "Watchdog was not ticked for")
+
+	rc.Tick()
+	rc.check()
+	isHealthy, _ = rc.IsHealthy()
+	assert.True(t, isHealthy)
+}
+
+func TestRuntimeCheckerStart(t *testing.T) {
+	rc := NewRuntimeChecker(RuntimeLimits{MaxGoroutines: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	rc.Start(ctx, time.Millisecond*10)
+
+	isHealthy, reason := rc.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, reason, "Too many goroutines")
+}
+
+func TestCombine(t *testing.T) {
+	errStream := errs.NewErrStream(0)
+	lis := NewErrsListener(0, time.Minute, errStream)
+	rc := NewRuntimeChecker(RuntimeLimits{})
+	rc.readStats = func(sinceNumGC uint32) runtimeStats {
+		return runtimeStats{}
+	}
+
+	checker := Combine(lis, rc)
+
+	isHealthy, reason := checker.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", reason)
+
+	reasons := make(chan string, 1)
+	checker.SubscribeToUnhealthyChange(func(reason string) {
+		reasons <- reason
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go lis.Start(ctx)
+
+	errStream.Send(errors.New("some err"))
+	reason = <-reasons
+
+	isHealthy, combinedReason := checker.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Equal(t, reason, combinedReason)
+
+	rc.limits.MaxGoroutines = 1
+	rc.readStats = func(sinceNumGC uint32) runtimeStats {
+		return runtimeStats{goroutines: 2}
+	}
+	rc.check()
+	assert.Equal(t, "Too many goroutines 2, the limit is 1", <-reasons)
+
+	_, combinedReason = checker.IsHealthy()
+	assert.Equal(t, reason+", Too many goroutines 2, the limit is 1", combinedReason)
+}
+
+func TestReadRuntimeStats(t *testing.T) {
+	stats := readRuntimeStats(0)
+	assert.True(t, stats.goroutines > 0)
+	assert.True(t, stats.heapBytes > 0)
+}
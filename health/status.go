@@ -0,0 +1,62 @@
+package health
+
+import (
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+)
+
+// State of the health
+type State string
+
+const (
+	// StateHealthy service is healthy
+	StateHealthy State = "healthy"
+	// StateUnhealthy service is unhealthy and should be restarted
+	StateUnhealthy State = "unhealthy"
+)
+
+const defaultSamplesCount = 5
+
+// ErrorSample one of the recent errors
+type ErrorSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Category  string    `json:"category,omitempty"`
+	Message   string    `json:"message"`
+	Count     int       `json:"count"`
+}
+
+// Status detailed health state, e.g. for json output of the health handler
+type Status struct {
+	State       State         `json:"state"`
+	Since       time.Time     `json:"since"`
+	Reason      string        `json:"reason,omitempty"`
+	WindowStart time.Time     `json:"windowStart"`
+	WindowEnd   time.Time     `json:"windowEnd"`
+	ErrorCount  int           `json:"errorCount"`
+	Threshold   int           `json:"threshold"`
+	Samples     []ErrorSample `json:"samples"`
+	// Override describes the manual override which forced State, e.g. of admin.Overrides
+	Override string `json:"override,omitempty"`
+}
+
+// StatusProvider is implemented by checkers which can give a detailed health status
+type StatusProvider interface {
+	Status() Status
+}
+
+// WithSamplesCount sets amount of the last errors kept for the detailed status, defaults to 5
+func WithSamplesCount(count int) ListenerOption {
+	return func(l *ErrsListener) {
+		l.samplesCount = count
+	}
+}
+
+func newErrorSample(ep errs.ErrPayload) ErrorSample {
+	return ErrorSample{
+		Timestamp: time.Unix(ep.Timestamp, 0).UTC(),
+		Category:  ep.Category,
+		Message:   ep.Err.Error(),
+		Count:     ep.Weight(),
+	}
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	errStream := errs.NewErrStream(0)
+	l := NewErrsListener(2, time.Minute, errStream, WithSamplesCount(2))
+
+	st := l.Status()
+	assert.Equal(t, StateHealthy, st.State)
+	assert.Equal(t, 0, st.ErrorCount)
+	assert.Equal(t, 2, st.Threshold)
+	assert.True(t, st.WindowStart.IsZero())
+	assert.Len(t, st.Samples, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	go l.Start(ctx)
+
+	errStream.SendWithCategory(errors.New("err1"), "db")
+	errStream.SendWithCategory(errors.New("err2"), "cache")
+	errStream.SendPayload(errs.ErrPayload{Err: errors.New("err3"), Timestamp: time.Now().Unix(), Count: 2})
+
+	<-ctx.Done()
+
+	st = l.Status()
+	assert.Equal(t, StateUnhealthy, st.State)
+	assert.Contains(t, st.Reason, "Too many critical errors 4")
+	assert.Equal(t, 4, st.ErrorCount)
+	assert.Equal(t, time.Unix(l.firstErrorTimestamp, 0).UTC(), st.WindowStart)
+	assert.Equal(t, st.WindowStart.Add(time.Minute), st.WindowEnd)
+	assert.False(t, st.Since.Before(st.WindowStart))
+
+	assert.Len(t, st.Samples, 2)
+	assert.Equal(t, "err2", st.Samples[0].Message)
+	assert.Equal(t, "cache", st.Samples[0].Category)
+	assert.Equal(t, 1, st.Samples[0].Count)
+	assert.Equal(t, "err3", st.Samples[1].Message)
+	assert.Equal(t, 2, st.Samples[1].Count)
+	assert.False(t, st.Samples[1].Timestamp.IsZero())
+}
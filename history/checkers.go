@@ -0,0 +1,98 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/breathbath/healthReadyChecks/logging"
+	"github.com/breathbath/healthReadyChecks/ready"
+)
+
+// Source gives the history of a tracked checker
+type Source interface {
+	History() Snapshot
+}
+
+// HealthTracker health.Checker which records results of the wrapped checker and suppresses flapping health
+type HealthTracker struct {
+	checker health.Checker
+	tracker *tracker
+}
+
+// NewHealthTracker constructor for HealthTracker, the reported state is healthy until FailureThreshold consecutive failures
+func NewHealthTracker(checker health.Checker, opts Options) *HealthTracker {
+	return &HealthTracker{
+		checker: checker,
+		tracker: newTracker(opts, true),
+	}
+}
+
+// IsHealthy health.Checker implementation, every call is recorded as a probe
+func (ht *HealthTracker) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	isHealthy, unhealthyReason = ht.checker.IsHealthy()
+
+	return ht.tracker.observe(isHealthy, unhealthyReason)
+}
+
+// SubscribeToUnhealthyChange health.Checker implementation, subscribes to the wrapped checker
+func (ht *HealthTracker) SubscribeToUnhealthyChange(sf func(reason string)) {
+	ht.checker.SubscribeToUnhealthyChange(sf)
+}
+
+// History gives the reported health with recent probes and transitions
+func (ht *HealthTracker) History() Snapshot {
+	return ht.tracker.snapshot()
+}
+
+// ReadyTracker ready.Checker which records results of the wrapped checker and suppresses flapping readiness
+type ReadyTracker struct {
+	checker ready.Checker
+	tracker *tracker
+}
+
+// NewReadyTracker constructor for ReadyTracker, the reported state is not ready until SuccessThreshold consecutive successes
+func NewReadyTracker(checker ready.Checker, opts Options) *ReadyTracker {
+	return &ReadyTracker{
+		checker: checker,
+		tracker: newTracker(opts, false),
+	}
+}
+
+// IsReady ready.Checker implementation, every call is recorded as a probe
+func (rt *ReadyTracker) IsReady(ctx context.Context) (isReady bool, err error) {
+	isReady, err = rt.checker.IsReady(ctx)
+
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+
+	isReady, reason = rt.tracker.observe(isReady, reason)
+	if isReady {
+		return true, nil
+	}
+	if reason == "" {
+		return false, nil
+	}
+
+	return false, errors.New(reason)
+}
+
+// History gives the reported readiness with recent probes and transitions
+func (rt *ReadyTracker) History() Snapshot {
+	return rt.tracker.snapshot()
+}
+
+// NewHandler gives http.Handler which renders the history as json, e.g. to be served at /healthz/history
+func NewHandler(source Source) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(source.History())
+		if err != nil {
+			logging.L.ErrorF("Failed to write body: %v", err)
+		}
+	})
+}
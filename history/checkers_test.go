@@ -0,0 +1,102 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type healthCheckerMock struct {
+	isHealthy bool
+	reason    string
+	subscrF   func(reason string)
+}
+
+// IsHealthy health.Checker implementation
+func (hcm *healthCheckerMock) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	return hcm.isHealthy, hcm.reason
+}
+
+// SubscribeToUnhealthyChange health.Checker implementation
+func (hcm *healthCheckerMock) SubscribeToUnhealthyChange(sf func(reason string)) {
+	hcm.subscrF = sf
+}
+
+type readyCheckerMock struct {
+	isReady bool
+	err     error
+}
+
+// IsReady ready.Checker implementation
+func (rcm *readyCheckerMock) IsReady(ctx context.Context) (isReady bool, err error) {
+	return rcm.isReady, rcm.err
+}
+
+func TestHealthTracker(t *testing.T) {
+	hc := &healthCheckerMock{isHealthy: false, reason: "too many errors"}
+	ht := NewHealthTracker(hc, Options{FailureThreshold: 2})
+
+	isHealthy, reason := ht.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", reason)
+
+	isHealthy, reason = ht.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Equal(t, "too many errors", reason)
+
+	ht.SubscribeToUnhealthyChange(func(reason string) {})
+	assert.NotNil(t, hc.subscrF)
+
+	snapshot := ht.History()
+	assert.False(t, snapshot.IsOK)
+	assert.Len(t, snapshot.Probes, 2)
+	assert.Len(t, snapshot.Transitions, 1)
+}
+
+func TestReadyTracker(t *testing.T) {
+	rc := &readyCheckerMock{isReady: true}
+	rt := NewReadyTracker(rc, Options{SuccessThreshold: 2, FailureThreshold: 2})
+
+	isReady, err := rt.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.NoError(t, err)
+
+	isReady, err = rt.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+
+	rc.isReady = false
+	rc.err = errors.New("db is down")
+
+	isReady, err = rt.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+
+	isReady, err = rt.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "db is down")
+}
+
+func TestHandler(t *testing.T) {
+	ht := NewHealthTracker(&healthCheckerMock{isHealthy: false, reason: "too many errors"}, Options{})
+	ht.IsHealthy()
+
+	rec := httptest.NewRecorder()
+	NewHandler(ht).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz/history", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	snapshot := Snapshot{}
+	err := json.Unmarshal(rec.Body.Bytes(), &snapshot)
+	assert.NoError(t, err)
+	assert.False(t, snapshot.IsOK)
+	assert.Equal(t, "too many errors", snapshot.Reason)
+	assert.Len(t, snapshot.Probes, 1)
+	assert.Len(t, snapshot.Transitions, 1)
+}
@@ -0,0 +1,164 @@
+package history
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultCapacity = 100
+
+// Options of history trackers
+type Options struct {
+	// Capacity max amount of kept probe results and transitions each, older entries are dropped, defaults to 100
+	Capacity int
+	// FailureThreshold amount of consecutive failed probes which switch the reported state to failure, defaults to 1
+	FailureThreshold int
+	// SuccessThreshold amount of consecutive successful probes which switch the reported state to success, defaults to 1
+	SuccessThreshold int
+}
+
+// Entry a single probe result or a transition of the reported state
+type Entry struct {
+	Time   time.Time `json:"time"`
+	IsOK   bool      `json:"ok"`
+	Reason string    `json:"reason,omitempty"`
+	// IsSuppressed is true for probe results which differ from the reported state as thresholds were not reached yet
+	IsSuppressed bool `json:"suppressed,omitempty"`
+}
+
+// Snapshot current reported state with the recent probe results and transitions, oldest entries first
+type Snapshot struct {
+	IsOK        bool      `json:"ok"`
+	Since       time.Time `json:"since"`
+	Reason      string    `json:"reason,omitempty"`
+	Probes      []Entry   `json:"probes"`
+	Transitions []Entry   `json:"transitions"`
+	// SuppressedCount amount of probe results which didn't change the reported state due to flap detection
+	SuppressedCount int `json:"suppressedCount"`
+}
+
+// ring bounded collection of entries which overwrites the oldest ones
+type ring struct {
+	entries []Entry
+	next    int
+	isFull  bool
+}
+
+func newRing(capacity int) *ring {
+	return &ring{entries: make([]Entry, capacity)}
+}
+
+func (r *ring) add(e Entry) {
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.isFull = true
+	}
+}
+
+func (r *ring) list() []Entry {
+	if !r.isFull {
+		res := make([]Entry, r.next)
+		copy(res, r.entries[:r.next])
+		return res
+	}
+
+	res := make([]Entry, 0, len(r.entries))
+	res = append(res, r.entries[r.next:]...)
+	return append(res, r.entries[:r.next]...)
+}
+
+// tracker keeps history of probe results and decides on the reported state
+type tracker struct {
+	lock             sync.Mutex
+	failureThreshold int
+	successThreshold int
+	probes           *ring
+	transitions      *ring
+	isOK             bool
+	since            time.Time
+	reason           string
+	consecutive      int
+	suppressedCount  int
+}
+
+func newTracker(opts Options, isOK bool) *tracker {
+	if opts.Capacity <= 0 {
+		opts.Capacity = defaultCapacity
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 1
+	}
+	if opts.SuccessThreshold <= 0 {
+		opts.SuccessThreshold = 1
+	}
+
+	return &tracker{
+		lock:             sync.Mutex{},
+		failureThreshold: opts.FailureThreshold,
+		successThreshold: opts.SuccessThreshold,
+		probes:           newRing(opts.Capacity),
+		transitions:      newRing(opts.Capacity),
+		isOK:             isOK,
+		since:            time.Now().UTC(),
+	}
+}
+
+// observe records the probe result and gives the reported state with its reason
+func (t *tracker) observe(isOK bool, reason string) (reportedOK bool, reportedReason string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := time.Now().UTC()
+	if !isOK {
+		t.reason = reason
+	}
+
+	if isOK == t.isOK {
+		t.consecutive = 0
+		t.probes.add(Entry{Time: now, IsOK: isOK, Reason: reason})
+		return t.isOK, t.reportedReason()
+	}
+
+	t.consecutive++
+	threshold := t.failureThreshold
+	if isOK {
+		threshold = t.successThreshold
+	}
+
+	if t.consecutive < threshold {
+		t.suppressedCount++
+		t.probes.add(Entry{Time: now, IsOK: isOK, Reason: reason, IsSuppressed: true})
+		return t.isOK, t.reportedReason()
+	}
+
+	t.consecutive = 0
+	t.isOK = isOK
+	t.since = now
+	t.probes.add(Entry{Time: now, IsOK: isOK, Reason: reason})
+	t.transitions.add(Entry{Time: now, IsOK: isOK, Reason: reason})
+
+	return t.isOK, t.reportedReason()
+}
+
+func (t *tracker) reportedReason() string {
+	if t.isOK {
+		return ""
+	}
+
+	return t.reason
+}
+
+func (t *tracker) snapshot() Snapshot {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return Snapshot{
+		IsOK:            t.isOK,
+		Since:           t.since,
+		Reason:          t.reportedReason(),
+		Probes:          t.probes.list(),
+		Transitions:     t.transitions.list(),
+		SuppressedCount: t.suppressedCount,
+	}
+}
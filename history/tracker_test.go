@@ -0,0 +1,80 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	r := newRing(3)
+	assert.Len(t, r.list(), 0)
+
+	for _, reason := range []string{"1", "2"} {
+		r.add(Entry{Reason: reason})
+	}
+	assert.Equal(t, []Entry{{Reason: "1"}, {Reason: "2"}}, r.list())
+
+	for _, reason := range []string{"3", "4", "5"} {
+		r.add(Entry{Reason: reason})
+	}
+	assert.Equal(t, []Entry{{Reason: "3"}, {Reason: "4"}, {Reason: "5"}}, r.list())
+}
+
+func TestTrackerWithoutThresholds(t *testing.T) {
+	tr := newTracker(Options{}, true)
+
+	isOK, reason := tr.observe(false, "err1")
+	assert.False(t, isOK)
+	assert.Equal(t, "err1", reason)
+
+	isOK, reason = tr.observe(true, "")
+	assert.True(t, isOK)
+	assert.Equal(t, "", reason)
+
+	snapshot := tr.snapshot()
+	assert.True(t, snapshot.IsOK)
+	assert.Len(t, snapshot.Probes, 2)
+	assert.Len(t, snapshot.Transitions, 2)
+	assert.Equal(t, 0, snapshot.SuppressedCount)
+}
+
+func TestTrackerFlapDetection(t *testing.T) {
+	tr := newTracker(Options{FailureThreshold: 3, SuccessThreshold: 2, Capacity: 10}, true)
+
+	probes := []struct {
+		isOK           bool
+		reason         string
+		expectedOK     bool
+		expectedReason string
+	}{
+		{isOK: false, reason: "err1", expectedOK: true},
+		{isOK: false, reason: "err2", expectedOK: true},
+		{isOK: true, expectedOK: true},
+		{isOK: false, reason: "err3", expectedOK: true},
+		{isOK: false, reason: "err4", expectedOK: true},
+		{isOK: false, reason: "err5", expectedOK: false, expectedReason: "err5"},
+		{isOK: true, expectedOK: false, expectedReason: "err5"},
+		{isOK: false, reason: "err6", expectedOK: false, expectedReason: "err6"},
+		{isOK: true, expectedOK: false, expectedReason: "err6"},
+		{isOK: true, expectedOK: true},
+	}
+
+	for i, p := range probes {
+		isOK, reason := tr.observe(p.isOK, p.reason)
+		assert.Equal(t, p.expectedOK, isOK, "probe %d", i)
+		assert.Equal(t, p.expectedReason, reason, "probe %d", i)
+	}
+
+	snapshot := tr.snapshot()
+	assert.True(t, snapshot.IsOK)
+	assert.Len(t, snapshot.Probes, 10)
+	assert.Equal(t, 6, snapshot.SuppressedCount)
+	assert.True(t, snapshot.Probes[0].IsSuppressed)
+	assert.False(t, snapshot.Probes[5].IsSuppressed)
+	assert.Len(t, snapshot.Transitions, 2)
+	assert.Equal(t, "err5", snapshot.Transitions[0].Reason)
+	assert.False(t, snapshot.Transitions[0].IsOK)
+	assert.True(t, snapshot.Transitions[1].IsOK)
+	assert.Equal(t, snapshot.Transitions[1].Time, snapshot.Since)
+}
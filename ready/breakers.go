@@ -0,0 +1,161 @@
+package ready
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+// BreakerState state of the circuit breaker of a test
+type BreakerState string
+
+const (
+	// BreakerClosed the test is executed as usual
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen the test is not executed and reported as not ready until the cooldown is over
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen a single trial execution is in progress, its result closes or opens the circuit again
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+const defaultBreakerCooldown = time.Second * 30
+
+// breakers keeps circuit breaker states of tests between evaluations
+type breakers struct {
+	lock   sync.Mutex
+	states map[string]*breakerState
+}
+
+type breakerState struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	lastErr  error
+}
+
+func (t Test) hasBreaker() bool {
+	return t.BreakerThreshold > 0
+}
+
+func (t Test) breakerCooldown() time.Duration {
+	if t.BreakerCooldown <= 0 {
+		return defaultBreakerCooldown
+	}
+
+	return t.BreakerCooldown
+}
+
+// allow tells if the test can be executed, isTrial is true for the single execution after the cooldown of an open circuit,
+// a not nil error is the reason of the short circuit
+func (b *breakers) allow(test Test) (isTrial bool, err error) {
+	if b == nil || !test.hasBreaker() {
+		return false, nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	st := b.state(test.Name)
+	switch st.state {
+	case BreakerOpen:
+		sinceOpen := time.Since(st.openedAt)
+		if sinceOpen < test.breakerCooldown() {
+			return false, fmt.Errorf("circuit breaker is open for %v, last error: %v", (test.breakerCooldown() - sinceOpen).Round(time.Millisecond), st.lastErr)
+		}
+		logging.L.InfoF("Circuit breaker of %s is half-open, will execute a trial check", test.Name)
+		st.state = BreakerHalfOpen
+		return true, nil
+	case BreakerHalfOpen:
+		return false, fmt.Errorf("circuit breaker is half-open, last error: %v", st.lastErr)
+	default:
+		return false, nil
+	}
+}
+
+// record updates the circuit breaker of the test with the result of its execution
+func (b *breakers) record(test Test, isReady bool, err error) {
+	if b == nil || !test.hasBreaker() {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	st := b.state(test.Name)
+	if isReady {
+		if st.state != BreakerClosed {
+			logging.L.InfoF("Circuit breaker of %s is closed", test.Name)
+		}
+		st.state = BreakerClosed
+		st.failures = 0
+		return
+	}
+
+	st.failures++
+	st.lastErr = err
+	if st.state == BreakerHalfOpen || st.failures >= test.BreakerThreshold {
+		logging.L.WarnF("Circuit breaker of %s is open after %d failures: %v", test.Name, st.failures, err)
+		st.state = BreakerOpen
+		st.openedAt = time.Now()
+	}
+}
+
+// cancelTrial opens the half-open circuit of the test again without waiting for a new cooldown, e.g. if the trial was not executed
+func (b *breakers) cancelTrial(test Test) {
+	if b == nil || !test.hasBreaker() {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	st := b.state(test.Name)
+	if st.state == BreakerHalfOpen {
+		st.state = BreakerOpen
+	}
+}
+
+// current gives the circuit breaker state of the test, empty for tests without a circuit breaker
+func (b *breakers) current(test Test) BreakerState {
+	if b == nil || !test.hasBreaker() {
+		return ""
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state(test.Name).state
+}
+
+func (b *breakers) state(name string) *breakerState {
+	if b.states == nil {
+		b.states = map[string]*breakerState{}
+	}
+
+	st, ok := b.states[name]
+	if !ok {
+		st = &breakerState{state: BreakerClosed}
+		b.states[name] = st
+	}
+
+	return st
+}
+
+// retain forgets circuit breakers of tests which are not in the list anymore
+func (b *breakers) retain(tests []Test) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	names := make(map[string]bool, len(tests))
+	for _, t := range tests {
+		names[t.Name] = true
+	}
+
+	for name := range b.states {
+		if !names[name] {
+			delete(b.states, name)
+		}
+	}
+}
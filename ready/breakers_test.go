@@ -0,0 +1,116 @@
+package ready
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	calls := 0
+	testErr := errors.New("db is down")
+	checker := NewTestChecker([]Test{
+		{
+			TestFunc: func() error {
+				calls++
+				return testErr
+			},
+			Name:             "db",
+			BreakerThreshold: 2,
+			BreakerCooldown:  time.Millisecond * 50,
+		},
+	},
+		3,
+		time.Millisecond,
+		sleep.NewSleeperMock(),
+	)
+
+	for i := 0; i < 2; i++ {
+		isReady, err := checker.IsReady(context.Background())
+		assert.False(t, isReady)
+		assert.EqualError(t, err, "Readiness probe failed for db: db is down")
+	}
+	assert.Equal(t, 6, calls, "every evaluation of a closed circuit should retry")
+
+	report := checker.Report(context.Background())
+	assert.False(t, report.IsReady)
+	assert.Len(t, report.Tests, 1)
+	assert.Equal(t, BreakerOpen, report.Tests[0].Breaker)
+	assert.Contains(t, report.Tests[0].Error, "circuit breaker is open for")
+	assert.Contains(t, report.Tests[0].Error, "last error: db is down")
+	assert.Equal(t, 6, calls, "an open circuit should short circuit")
+
+	time.Sleep(time.Millisecond * 60)
+
+	report = checker.Report(context.Background())
+	assert.False(t, report.IsReady)
+	assert.Equal(t, BreakerOpen, report.Tests[0].Breaker)
+	assert.Equal(t, "db is down", report.Tests[0].Error)
+	assert.Equal(t, 7, calls, "a half-open circuit should execute a single trial")
+
+	testErr = nil
+	time.Sleep(time.Millisecond * 60)
+
+	report = checker.Report(context.Background())
+	assert.True(t, report.IsReady)
+	assert.Equal(t, TestReport{Name: "db", IsReady: true, Breaker: BreakerClosed}, report.Tests[0])
+	assert.Equal(t, 8, calls)
+}
+
+func TestCircuitBreakerHalfOpenAllowsSingleTrial(t *testing.T) {
+	b := &breakers{}
+	test := Test{Name: "db", BreakerThreshold: 1, BreakerCooldown: time.Millisecond}
+
+	b.record(test, false, errors.New("db is down"))
+	assert.Equal(t, BreakerOpen, b.current(test))
+
+	time.Sleep(time.Millisecond * 2)
+
+	isTrial, err := b.allow(test)
+	assert.True(t, isTrial)
+	assert.NoError(t, err)
+	assert.Equal(t, BreakerHalfOpen, b.current(test))
+
+	isTrial, err = b.allow(test)
+	assert.False(t, isTrial)
+	assert.EqualError(t, err, "circuit breaker is half-open, last error: db is down")
+
+	b.retain([]Test{})
+	assert.Equal(t, BreakerClosed, b.current(test))
+	assert.Equal(t, BreakerState(""), b.current(Test{Name: "db"}))
+}
+
+func TestCheckerReport(t *testing.T) {
+	checker := NewTestChecker([]Test{
+		{TestFunc: func() error { return nil }, Name: "cache"},
+		{TestFunc: func() error { return errors.New("db is down") }, Name: "db"},
+	},
+		1,
+		time.Millisecond,
+		sleep.NewSleeperMock(),
+	)
+
+	report := checker.Report(context.Background())
+	assert.Equal(t, Report{
+		IsReady: false,
+		Tests: []TestReport{
+			{Name: "cache", IsReady: true},
+			{Name: "db", IsReady: false, Error: "db is down"},
+		},
+	}, report)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report = NewTestChecker([]Test{
+		{TestFunc: func() error { time.Sleep(time.Millisecond * 50); return nil }, Name: "slow"},
+	}, 1, time.Millisecond, sleep.NewSleeperMock()).Report(ctx)
+	assert.False(t, report.IsReady)
+	assert.Equal(t, "ready tests failed due to the context timeout", report.Error)
+	assert.Equal(t, []TestReport{{Name: "slow", IsReady: false, Error: "ready test didn't complete before the context timeout"}}, report.Tests)
+
+	assert.Equal(t, Report{IsReady: true, Tests: []TestReport{}}, TestChecker{}.Report(context.Background()))
+}
@@ -0,0 +1,163 @@
+package ready
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+// ValidateDependencies checks that tests depend only on existing tests and that dependencies have no cycles
+func ValidateDependencies(tests []Test) error {
+	_, errs := resolveDependencies(tests)
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveDependencies gives indexes of dependencies of every test and errors of tests which can't be executed
+// because they depend on unknown tests or are part of a dependency cycle
+func resolveDependencies(tests []Test) (deps [][]int, errs []error) {
+	indexes := make(map[string]int, len(tests))
+	for i, t := range tests {
+		indexes[t.Name] = i
+	}
+
+	deps = make([][]int, len(tests))
+	errs = make([]error, len(tests))
+	for i, t := range tests {
+		for _, name := range t.DependsOn {
+			depIndex, ok := indexes[name]
+			if !ok {
+				errs[i] = fmt.Errorf("test %s depends on unknown test %s", t.Name, name)
+				continue
+			}
+			deps[i] = append(deps[i], depIndex)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(tests))
+	path := make([]int, 0, len(tests))
+
+	var visit func(i int)
+	visit = func(i int) {
+		states[i] = visiting
+		path = append(path, i)
+
+		for _, depIndex := range deps[i] {
+			switch states[depIndex] {
+			case unvisited:
+				visit(depIndex)
+			case visiting:
+				markCycle(tests, path, depIndex, errs)
+			}
+		}
+
+		path = path[:len(path)-1]
+		states[i] = visited
+	}
+
+	for i := range tests {
+		if states[i] == unvisited {
+			visit(i)
+		}
+	}
+
+	return deps, errs
+}
+
+// markCycle sets an error for every test of the cycle which starts at the start index of the path
+func markCycle(tests []Test, path []int, start int, errs []error) {
+	cycleStart := 0
+	for pos, i := range path {
+		if i == start {
+			cycleStart = pos
+			break
+		}
+	}
+
+	names := make([]string, 0, len(path)-cycleStart+1)
+	for _, i := range path[cycleStart:] {
+		names = append(names, tests[i].Name)
+	}
+	names = append(names, tests[start].Name)
+
+	err := fmt.Errorf("dependency cycle %s", strings.Join(names, " -> "))
+	for _, i := range path[cycleStart:] {
+		errs[i] = err
+	}
+}
+
+// gate lets tests of a single evaluation wait for the reported results of their dependencies
+type gate struct {
+	deps    [][]int
+	errs    []error
+	done    []chan struct{}
+	results []result
+}
+
+func newGate(tests []Test, results []result) *gate {
+	deps, errs := resolveDependencies(tests)
+	done := make([]chan struct{}, len(tests))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	return &gate{
+		deps:    deps,
+		errs:    errs,
+		done:    done,
+		results: results,
+	}
+}
+
+// wait blocks until all dependencies of the test are completed, gives a not nil error if the test must be skipped,
+// isCancelled is true if the context is done before
+func (g *gate) wait(ctx context.Context, index int) (blockedErr error, isCancelled bool) {
+	if g.errs[index] != nil {
+		return g.errs[index], false
+	}
+
+	blockers := make([]string, 0, len(g.deps[index]))
+	for _, depIndex := range g.deps[index] {
+		select {
+		case <-g.done[depIndex]:
+		case <-ctx.Done():
+			return nil, true
+		}
+
+		if !g.results[depIndex].isReady {
+			blockers = append(blockers, g.results[depIndex].test.Name)
+		}
+	}
+
+	if len(blockers) > 0 {
+		return fmt.Errorf("blocked by %s", strings.Join(blockers, ", ")), false
+	}
+
+	return nil, false
+}
+
+// complete stores the reported result of a test and unblocks its dependents,
+// a second result for the same index is a bug of its producer, it's logged and ignored instead of panicking in the probe
+func (g *gate) complete(res result) {
+	select {
+	case <-g.done[res.index]:
+		logging.L.ErrorF("Ignoring a repeated result of ready test %s with index %d", res.test.Name, res.index)
+		return
+	default:
+	}
+
+	g.results[res.index] = res
+	close(g.done[res.index])
+}
@@ -0,0 +1,166 @@
+package ready
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	assert.NoError(t, ValidateDependencies([]Test{
+		{Name: "tcp"},
+		{Name: "table", DependsOn: []string{"tcp"}},
+		{Name: "topics", DependsOn: []string{"tcp", "table"}},
+	}))
+
+	assert.EqualError(
+		t,
+		ValidateDependencies([]Test{{Name: "table", DependsOn: []string{"tcp"}}}),
+		"test table depends on unknown test tcp",
+	)
+
+	assert.EqualError(
+		t,
+		ValidateDependencies([]Test{
+			{Name: "a", DependsOn: []string{"b"}},
+			{Name: "b", DependsOn: []string{"c"}},
+			{Name: "c", DependsOn: []string{"a"}},
+		}),
+		"dependency cycle a -> b -> c -> a",
+	)
+
+	_, err := BuildTestChecker([]Test{{Name: "self", DependsOn: []string{"self"}}}, 1, time.Millisecond, sleep.NewSleeperMock())
+	assert.EqualError(t, err, "dependency cycle self -> self")
+}
+
+func TestDependenciesOrder(t *testing.T) {
+	lock := sync.Mutex{}
+	executed := []string{}
+	testFunc := func(name string, err error) func() error {
+		return func() error {
+			time.Sleep(time.Millisecond * 10)
+			lock.Lock()
+			defer lock.Unlock()
+			executed = append(executed, name)
+			return err
+		}
+	}
+
+	checker, err := BuildTestChecker([]Test{
+		{Name: "table", TestFunc: testFunc("table", nil), DependsOn: []string{"tcp"}},
+		{Name: "tcp", TestFunc: testFunc("tcp", nil)},
+		{Name: "topics", TestFunc: testFunc("topics", nil), DependsOn: []string{"table", "tcp"}},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+	assert.NoError(t, err)
+
+	isReady, err := checker.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tcp", "table", "topics"}, executed)
+}
+
+func TestBlockedDependents(t *testing.T) {
+	executed := make(chan string, 4)
+	checker := NewTestChecker([]Test{
+		{Name: "broker", TestFunc: func() error { executed <- "broker"; return errors.New("connection refused") }},
+		{Name: "topics", TestFunc: func() error { executed <- "topics"; return nil }, DependsOn: []string{"broker"}},
+		{Name: "consumer", TestFunc: func() error { executed <- "consumer"; return nil }, DependsOn: []string{"topics"}},
+		{Name: "cache", TestFunc: func() error { executed <- "cache"; return nil }},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	isReady, err := checker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(
+		t,
+		err,
+		"Readiness probe failed for broker: connection refused, Readiness probe failed for topics: blocked by broker, "+
+			"Readiness probe failed for consumer: blocked by topics",
+	)
+	assert.ElementsMatch(t, []string{"broker", "cache"}, []string{<-executed, <-executed})
+	assert.Len(t, executed, 0)
+
+	report := checker.Report(context.Background())
+	assert.Equal(t, TestReport{Name: "topics", IsReady: false, Error: "blocked by broker", IsBlocked: true}, report.Tests[1])
+}
+
+func TestCyclesAtRuntime(t *testing.T) {
+	checker := NewTestChecker([]Test{
+		{Name: "a", TestFunc: func() error { return nil }, DependsOn: []string{"b"}},
+		{Name: "b", TestFunc: func() error { return nil }, DependsOn: []string{"a"}},
+		{Name: "c", TestFunc: func() error { return nil }, DependsOn: []string{"a"}},
+		{Name: "d", TestFunc: func() error { return nil }, DependsOn: []string{"unknown"}},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	isReady, err := checker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(
+		t,
+		err,
+		"Readiness probe failed for a: dependency cycle a -> b -> a, Readiness probe failed for b: dependency cycle a -> b -> a, "+
+			"Readiness probe failed for c: blocked by a, Readiness probe failed for d: test d depends on unknown test unknown",
+	)
+}
+
+func TestDependenciesContextTimeout(t *testing.T) {
+	checker := NewTestChecker([]Test{
+		{Name: "slow", TestFunc: func() error { time.Sleep(time.Millisecond * 100); return nil }},
+		{Name: "dependent", TestFunc: func() error { return nil }, DependsOn: []string{"slow"}},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	isReady, err := checker.IsReady(ctx)
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "ready tests failed due to the context timeout")
+}
+
+func TestToleratedDependency(t *testing.T) {
+	evaluation := 0
+	executed := make(chan string, 6)
+	checker := NewTestChecker([]Test{
+		{Name: "cache", TestFunc: func() error { return nil }},
+		{
+			Name: "broker",
+			TestFunc: func() error {
+				if evaluation == 1 {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+			FailureThreshold: 2,
+		},
+		{Name: "topics", TestFunc: func() error { executed <- "topics"; return nil }, DependsOn: []string{"broker"}},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	for evaluation = 0; evaluation < 2; evaluation++ {
+		report, err := checker.ReportFor(context.Background(), Selection{})
+		assert.NoError(t, err, "evaluation %d", evaluation)
+		assert.True(t, report.IsReady, "evaluation %d", evaluation)
+		assert.Equal(
+			t,
+			[]TestReport{{Name: "cache", IsReady: true}, {Name: "broker", IsReady: true}, {Name: "topics", IsReady: true}},
+			report.Tests,
+			"evaluation %d",
+			evaluation,
+		)
+	}
+	assert.Equal(t, []string{"topics", "topics"}, []string{<-executed, <-executed})
+}
+
+func TestGateIgnoresRepeatedResults(t *testing.T) {
+	tests := []Test{{Name: "a"}, {Name: "b"}}
+	g := newGate(tests, pendingResults(tests))
+
+	g.complete(result{index: 0, test: tests[0], isReady: true})
+	assert.NotPanics(t, func() {
+		g.complete(result{index: 0, test: tests[1], isReady: false})
+	})
+	assert.Equal(t, "a", g.results[0].test.Name)
+	assert.True(t, g.results[0].isReady)
+}
@@ -0,0 +1,127 @@
+package ready
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// CheckerOption configures TestChecker at construction
+type CheckerOption func(cs *checkerState)
+
+// WithSingleFlight makes concurrent IsReady and Report calls share one in-progress evaluation instead of running all tests for every call,
+// the shared evaluation runs until the last waiting call is done, so a call with a short timeout doesn't cancel it for others
+func WithSingleFlight() CheckerOption {
+	return func(cs *checkerState) {
+		cs.isSingleFlight = true
+	}
+}
+
+// WithMaxConcurrency limits amount of test executions running at the same time across all evaluations, 0 means unlimited
+func WithMaxConcurrency(maxConcurrency int) CheckerOption {
+	return func(cs *checkerState) {
+		if maxConcurrency > 0 {
+			cs.settings.limiter = make(chan struct{}, maxConcurrency)
+		}
+	}
+}
+
+// flight an evaluation shared by concurrent callers, it runs on its own context which is cancelled once no caller waits for it
+type flight struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+	done    chan struct{}
+	results []result
+	isDone  bool
+}
+
+// flights coalesces concurrent evaluations
+type flights struct {
+	lock    sync.Mutex
+	current *flight
+}
+
+// share joins the in-progress evaluation or starts a new one with the evaluate func, every caller waits until its own context is done,
+// so the shared evaluation is bound to the longest waiting caller, callers whose context is done before the shared evaluation completes
+// get the results of not completed tests
+func (fs *flights) share(ctx context.Context, tests []Test, evaluate func(ctx context.Context) ([]result, bool)) (results []result, isDone bool) {
+	fs.lock.Lock()
+	f := fs.current
+	if f == nil {
+		flightCtx, cancel := context.WithCancel(context.Background())
+		f = &flight{ctx: flightCtx, cancel: cancel, done: make(chan struct{})}
+		fs.current = f
+		go fs.run(f, evaluate)
+	}
+	f.waiters++
+	fs.lock.Unlock()
+
+	select {
+	case <-f.done:
+		results = make([]result, len(f.results))
+		copy(results, f.results)
+		return results, f.isDone
+	case <-ctx.Done():
+		fs.leave(f)
+		return pendingResults(tests), false
+	}
+}
+
+func (fs *flights) run(f *flight, evaluate func(ctx context.Context) ([]result, bool)) {
+	results, isDone := evaluate(f.ctx)
+
+	fs.lock.Lock()
+	if fs.current == f {
+		fs.current = nil
+	}
+	f.results, f.isDone = results, isDone
+	fs.lock.Unlock()
+
+	f.cancel()
+	close(f.done)
+}
+
+// leave cancels the flight if the caller was the last one waiting for it, later callers start a new flight
+func (fs *flights) leave(f *flight) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	f.waiters--
+	if f.waiters > 0 {
+		return
+	}
+	if fs.current == f {
+		fs.current = nil
+	}
+	f.cancel()
+}
+
+func pendingResults(tests []Test) []result {
+	results := make([]result, len(tests))
+	for i, test := range tests {
+		results[i] = result{index: i, test: test, isReady: false, err: errors.New("ready test didn't complete before the context timeout")}
+	}
+
+	return results
+}
+
+// acquire takes a test execution slot, isCancelled is true if the context is done before a slot is free
+func (rc checkerSettings) acquire(ctx context.Context) (isCancelled bool) {
+	if rc.limiter == nil {
+		return false
+	}
+
+	select {
+	case rc.limiter <- struct{}{}:
+		return false
+	case <-ctx.Done():
+		return true
+	}
+}
+
+func (rc checkerSettings) release() {
+	if rc.limiter != nil {
+		<-rc.limiter
+	}
+}
@@ -0,0 +1,176 @@
+package ready
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleFlight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	checker := NewTestChecker([]Test{
+		{
+			TestFunc: func() error {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return nil
+			},
+			Name: "db",
+		},
+	}, 1, time.Millisecond, sleep.NewSleeperMock(), WithSingleFlight())
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			isReady, err := checker.IsReady(context.Background())
+			assert.True(t, isReady)
+			assert.NoError(t, err)
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	isReady, _ := checker.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "a completed evaluation should not be reused")
+}
+
+func TestSingleFlightFollowerTimeout(t *testing.T) {
+	release := make(chan struct{})
+	checker := NewTestChecker([]Test{
+		{
+			TestFunc: func() error {
+				<-release
+				return nil
+			},
+			Name: "db",
+		},
+	}, 1, time.Millisecond, sleep.NewSleeperMock(), WithSingleFlight())
+
+	leaderDone := make(chan bool)
+	go func() {
+		isReady, _ := checker.IsReady(context.Background())
+		leaderDone <- isReady
+	}()
+	time.Sleep(time.Millisecond * 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	report := checker.Report(ctx)
+	assert.False(t, report.IsReady)
+	assert.Equal(t, "ready test didn't complete before the context timeout", report.Tests[0].Error)
+
+	close(release)
+	assert.True(t, <-leaderDone)
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	var running, maxRunning int32
+	testFunc := func() error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			observed := atomic.LoadInt32(&maxRunning)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 5)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+
+	tests := make([]Test, 0, 5)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		tests = append(tests, Test{TestFunc: testFunc, Name: name})
+	}
+	checker := NewTestChecker(tests, 1, time.Millisecond, sleep.NewSleeperMock(), WithMaxConcurrency(2))
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			isReady, err := checker.IsReady(context.Background())
+			assert.True(t, isReady)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+}
+
+func TestMaxConcurrencyContextTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	checker := NewTestChecker([]Test{
+		{TestFunc: func() error { <-release; return nil }, Name: "blocking"},
+		{TestFunc: func() error { return nil }, Name: "waiting", BreakerThreshold: 1},
+	}, 1, time.Millisecond, sleep.NewSleeperMock(), WithMaxConcurrency(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	report := checker.Report(ctx)
+	assert.False(t, report.IsReady)
+	assert.Equal(t, "ready tests failed due to the context timeout", report.Error)
+}
+
+func TestSingleFlightLeaderCancellation(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	checker := NewTestChecker([]Test{
+		{
+			ContextFunc: func(ctx context.Context) error {
+				atomic.AddInt32(&calls, 1)
+				select {
+				case <-release:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+			Name: "db",
+		},
+	}, 1, time.Millisecond, sleep.NewSleeperMock(), WithSingleFlight())
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan bool)
+	go func() {
+		isReady, _ := checker.IsReady(leaderCtx)
+		leaderDone <- isReady
+	}()
+	time.Sleep(time.Millisecond * 10)
+
+	followerCtx, cancelFollower := context.WithTimeout(context.Background(), time.Second)
+	defer cancelFollower()
+	followerDone := make(chan Report)
+	go func() {
+		followerDone <- checker.Report(followerCtx)
+	}()
+	time.Sleep(time.Millisecond * 10)
+
+	cancelLeader()
+	assert.False(t, <-leaderDone)
+
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+
+	report := <-followerDone
+	assert.True(t, report.IsReady)
+	assert.Equal(t, "", report.Error)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
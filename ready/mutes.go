@@ -0,0 +1,163 @@
+package ready
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+// Mute of a test which is reported as ready without execution, e.g. for a known flaky dependency during an incident
+type Mute struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	// Until the test is muted, nil means until Unmute
+	Until *time.Time `json:"until,omitempty"`
+}
+
+func (m Mute) isExpired(now time.Time) bool {
+	return m.Until != nil && !now.Before(*m.Until)
+}
+
+// mutes keeps muted tests between evaluations
+type mutes struct {
+	lock    sync.Mutex
+	entries map[string]Mute
+}
+
+func (ms *mutes) get(name string) (Mute, bool) {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	m, ok := ms.entries[name]
+	if !ok {
+		return Mute{}, false
+	}
+	if m.isExpired(time.Now()) {
+		logging.L.InfoF("Mute of %s is expired", name)
+		delete(ms.entries, name)
+		return Mute{}, false
+	}
+
+	return m, true
+}
+
+func (ms *mutes) set(m Mute) {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	if ms.entries == nil {
+		ms.entries = map[string]Mute{}
+	}
+	ms.entries[m.Name] = m
+}
+
+func (ms *mutes) remove(name string) {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	delete(ms.entries, name)
+}
+
+func (ms *mutes) list() []Mute {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	now := time.Now()
+	res := make([]Mute, 0, len(ms.entries))
+	for name, m := range ms.entries {
+		if m.isExpired(now) {
+			delete(ms.entries, name)
+			continue
+		}
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+// retain forgets mutes of tests which are not in the list anymore
+func (ms *mutes) retain(tests []Test) {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	names := make(map[string]bool, len(tests))
+	for _, t := range tests {
+		names[t.Name] = true
+	}
+
+	for name := range ms.entries {
+		if !names[name] {
+			delete(ms.entries, name)
+		}
+	}
+}
+
+// Mute reports the test as ready without executing it until the time, zero time means until Unmute, tests which depend on it are executed,
+// the error wraps ErrUnknownTest if the test doesn't exist
+func (rc TestChecker) Mute(name, reason string, until time.Time) error {
+	if rc.state == nil {
+		return fmt.Errorf("%w %s", ErrUnknownTest, name)
+	}
+
+	isKnown := false
+	for _, t := range rc.settings().tests {
+		if t.Name == name {
+			isKnown = true
+			break
+		}
+	}
+	if !isKnown {
+		return fmt.Errorf("%w %s", ErrUnknownTest, name)
+	}
+
+	logging.L.WarnF("Ready test %s is muted: %s", name, reason)
+	m := Mute{Name: name, Reason: reason}
+	if !until.IsZero() {
+		m.Until = &until
+	}
+	rc.state.mutes.set(m)
+
+	return nil
+}
+
+// Unmute executes the muted test again
+func (rc TestChecker) Unmute(name string) {
+	if rc.state == nil {
+		return
+	}
+
+	logging.L.InfoF("Ready test %s is unmuted", name)
+	rc.state.mutes.remove(name)
+}
+
+// Muted gives mutes of tests ordered by names
+func (rc TestChecker) Muted() []Mute {
+	if rc.state == nil {
+		return []Mute{}
+	}
+
+	return rc.state.mutes.list()
+}
+
+func (rc TestChecker) mute(test Test) (Mute, bool) {
+	if rc.state == nil {
+		return Mute{}, false
+	}
+
+	return rc.state.mutes.get(test.Name)
+}
+
+// sendMuted gives the result of the muted test to the evaluation
+func sendMuted(ctx context.Context, index int, test Test, m Mute, wg *sync.WaitGroup, resultChan chan result) {
+	defer wg.Done()
+
+	logging.L.DebugF("%s is muted: %s", test.Name, m.Reason)
+	sendResult(ctx, resultChan, result{index: index, test: test, isReady: true, mute: &m})
+}
@@ -0,0 +1,88 @@
+package ready
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMute(t *testing.T) {
+	var kafkaCalls int32
+	checker := NewTestChecker([]Test{
+		{
+			TestFunc: func() error {
+				atomic.AddInt32(&kafkaCalls, 1)
+				return errors.New("broker is down")
+			},
+			Name:             "kafka",
+			FailureThreshold: 2,
+		},
+		{TestFunc: func() error { return nil }, Name: "consumer", DependsOn: []string{"kafka"}},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	err := checker.Mute("kafka", "known broker outage", time.Time{})
+	assert.NoError(t, err)
+
+	isReady, err := checker.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&kafkaCalls))
+
+	report := checker.Report(context.Background())
+	assert.True(t, report.IsReady)
+	assert.Equal(t, &Mute{Name: "kafka", Reason: "known broker outage"}, report.Tests[0].Mute)
+	assert.Nil(t, report.Tests[1].Mute)
+	assert.True(t, report.Tests[1].IsReady)
+
+	assert.Equal(t, []Mute{{Name: "kafka", Reason: "known broker outage"}}, checker.Muted())
+
+	checker.Unmute("kafka")
+	assert.Equal(t, []Mute{}, checker.Muted())
+
+	report = checker.Report(context.Background())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&kafkaCalls))
+	assert.False(t, report.IsReady)
+	assert.True(t, report.Tests[1].IsBlocked)
+}
+
+func TestMuteExpiry(t *testing.T) {
+	checker := NewTestChecker([]Test{
+		{TestFunc: func() error { return errors.New("db is down") }, Name: "db"},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	err := checker.Mute("db", "maintenance", time.Now().Add(time.Millisecond*20))
+	assert.NoError(t, err)
+
+	isReady, _ := checker.IsReady(context.Background())
+	assert.True(t, isReady)
+
+	time.Sleep(time.Millisecond * 30)
+
+	isReady, err = checker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "Readiness probe failed for db: db is down")
+	assert.Equal(t, []Mute{}, checker.Muted())
+}
+
+func TestMuteUnknownTest(t *testing.T) {
+	checker := NewTestChecker([]Test{{TestFunc: func() error { return nil }, Name: "db"}}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	err := checker.Mute("redis", "maintenance", time.Time{})
+	assert.True(t, errors.Is(err, ErrUnknownTest))
+	assert.EqualError(t, err, "unknown ready test redis")
+}
+
+func TestMutesAreForgottenOnReconfigure(t *testing.T) {
+	checker := NewTestChecker([]Test{{TestFunc: func() error { return nil }, Name: "db"}}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	err := checker.Mute("db", "maintenance", time.Time{})
+	assert.NoError(t, err)
+
+	checker.Reconfigure([]Test{{TestFunc: func() error { return nil }, Name: "cache"}}, 1, time.Millisecond)
+	assert.Equal(t, []Mute{}, checker.Muted())
+}
@@ -20,85 +20,337 @@ type Checker interface {
 // Test will wrap readiness func
 type Test struct {
 	TestFunc func() error
-	Name     string
+	// ContextFunc is used instead of TestFunc if set, the context is done when the evaluation is abandoned, e.g. when the probe times out
+	ContextFunc func(ctx context.Context) error
+	Name        string
+	// FailureThreshold amount of consecutive failed evaluations after which a ready test is reported as failed, 0 or 1 fails immediately
+	FailureThreshold int
+	// SuccessThreshold amount of consecutive successful evaluations after which a failed test is reported as ready again, 0 or 1 recovers immediately
+	SuccessThreshold int
+	// BreakerThreshold amount of consecutive failed evaluations which open the circuit breaker of the test, so it's reported
+	// as not ready without execution until the cooldown is over, 0 disables the circuit breaker
+	BreakerThreshold int
+	// BreakerCooldown time an open circuit breaker waits before it lets a single trial execution through, defaults to 30s
+	BreakerCooldown time.Duration
+	// DependsOn names of tests which must be ready before this test is executed, otherwise it's skipped as blocked
+	DependsOn []string
+}
+
+func (t Test) run(ctx context.Context) error {
+	if t.ContextFunc != nil {
+		return t.ContextFunc(ctx)
+	}
+
+	return t.TestFunc()
 }
 
 type result struct {
-	test    Test
-	isReady bool
-	err     error
+	index     int
+	test      Test
+	isReady   bool
+	isBlocked bool
+	mute      *Mute
+	err       error
+}
+
+// TestReport result of a single test
+type TestReport struct {
+	Name    string       `json:"name"`
+	IsReady bool         `json:"ready"`
+	Error   string       `json:"error,omitempty"`
+	Breaker BreakerState `json:"breaker,omitempty"`
+	// IsBlocked is true if the test was skipped as its dependencies are not ready
+	IsBlocked bool `json:"blocked,omitempty"`
+	// Mute is set if the test is muted and was reported as ready without execution, see TestChecker.Mute
+	Mute *Mute `json:"mute,omitempty"`
 }
 
-// TestChecker ready checks are based on the []Test collection where tests are run in parallel
+// Report results of all tests of an evaluation, Error is set if the evaluation didn't complete
+type Report struct {
+	IsReady bool         `json:"ready"`
+	Error   string       `json:"error,omitempty"`
+	Tests   []TestReport `json:"tests"`
+	// Excluded names of tests which were skipped by the Selection of ReportFor
+	Excluded []string `json:"excluded,omitempty"`
+	// Override describes the manual override which forced IsReady, e.g. of admin.Overrides, tests are replaced with a single OverrideTestName test
+	Override string `json:"override,omitempty"`
+}
+
+// OverrideTestName name of the test which replaces all tests of a Report with an Override
+const OverrideTestName = "override"
+
+// TestChecker ready checks are based on the []Test collection where tests are run in parallel,
+// copies of a TestChecker share the same settings, so Reconfigure affects all of them
 type TestChecker struct {
+	state *checkerState
+}
+
+type checkerState struct {
+	lock           sync.RWMutex
+	settings       checkerSettings
+	thresholds     thresholds
+	breakers       breakers
+	isSingleFlight bool
+	flights        flights
+	mutes          mutes
+}
+
+type checkerSettings struct {
 	tests         []Test
 	maxRetries    int
 	sleepInterval time.Duration
 	sleeper       sleep.Sleeper
+	limiter       chan struct{}
 }
 
 // NewTestChecker constructor, will try maxRetries and sleep sleepInterval with the sleep.Sleeper before failing ready check
-func NewTestChecker(tests []Test, maxRetries int, sleepInterval time.Duration, sleeper sleep.Sleeper) TestChecker {
-	return TestChecker{tests: tests, maxRetries: maxRetries, sleepInterval: sleepInterval, sleeper: sleeper}
+func NewTestChecker(tests []Test, maxRetries int, sleepInterval time.Duration, sleeper sleep.Sleeper, opts ...CheckerOption) TestChecker {
+	state := &checkerState{
+		lock: sync.RWMutex{},
+		settings: checkerSettings{
+			tests:         tests,
+			maxRetries:    maxRetries,
+			sleepInterval: sleepInterval,
+			sleeper:       sleeper,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(state)
+	}
+
+	return TestChecker{state: state}
+}
+
+// BuildTestChecker constructor like NewTestChecker which additionally validates dependencies of tests, see ValidateDependencies
+func BuildTestChecker(tests []Test, maxRetries int, sleepInterval time.Duration, sleeper sleep.Sleeper, opts ...CheckerOption) (TestChecker, error) {
+	err := ValidateDependencies(tests)
+	if err != nil {
+		return TestChecker{}, err
+	}
+
+	return NewTestChecker(tests, maxRetries, sleepInterval, sleeper, opts...), nil
+}
+
+// Reconfigure atomically replaces tests, retries and the sleep interval, evaluations which are in progress complete with the old settings
+func (rc TestChecker) Reconfigure(tests []Test, maxRetries int, sleepInterval time.Duration) {
+	if rc.state == nil {
+		return
+	}
+
+	rc.state.lock.Lock()
+	defer rc.state.lock.Unlock()
+
+	logging.L.InfoF("Ready checker is reconfigured to %d tests, %d retries, %v sleep interval", len(tests), maxRetries, sleepInterval)
+	rc.state.settings.tests = tests
+	rc.state.settings.maxRetries = maxRetries
+	rc.state.settings.sleepInterval = sleepInterval
+	rc.state.thresholds.retain(tests)
+	rc.state.breakers.retain(tests)
+	rc.state.mutes.retain(tests)
+}
+
+func (rc TestChecker) settings() checkerSettings {
+	if rc.state == nil {
+		return checkerSettings{}
+	}
+
+	rc.state.lock.RLock()
+	defer rc.state.lock.RUnlock()
+
+	return rc.state.settings
+}
+
+func (rc TestChecker) breakers() *breakers {
+	if rc.state == nil {
+		return nil
+	}
+
+	return &rc.state.breakers
 }
 
 // IsReady readiness implementation
 func (rc TestChecker) IsReady(ctx context.Context) (isReady bool, err error) {
+	results, isDone := rc.evaluate(ctx)
+	if !isDone {
+		return false, errors.New("ready tests failed due to the context timeout")
+	}
+
+	errs := make([]string, 0, len(results))
+	for _, res := range results {
+		if !res.isReady {
+			errs = append(errs, fmt.Sprintf("Readiness probe failed for %s: %v", res.test.Name, res.err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return true, nil
+	}
+
+	return false, errors.New(strings.Join(errs, ", "))
+}
+
+// Report evaluates tests like IsReady and gives the result of every test with its circuit breaker state,
+// tests which didn't complete before the context is done are reported as not ready
+func (rc TestChecker) Report(ctx context.Context) Report {
+	results, isDone := rc.evaluate(ctx)
+
+	return rc.buildReport(results, isDone)
+}
+
+func (rc TestChecker) buildReport(results []result, isDone bool) Report {
+	br := rc.breakers()
+
+	report := Report{IsReady: true, Tests: make([]TestReport, 0, len(results))}
+	if !isDone {
+		report.IsReady = false
+		report.Error = "ready tests failed due to the context timeout"
+	}
+
+	for _, res := range results {
+		tr := TestReport{Name: res.test.Name, IsReady: res.isReady, Breaker: br.current(res.test), IsBlocked: res.isBlocked, Mute: res.mute}
+		if res.err != nil {
+			tr.Error = res.err.Error()
+		}
+		if !res.isReady {
+			report.IsReady = false
+		}
+		report.Tests = append(report.Tests, tr)
+	}
+
+	return report
+}
+
+// evaluate runs all tests in parallel, tests with dependencies wait for them, and gives their results in the order of tests, isDone is false if the context
+// was done before all tests completed, results of such tests have a context error
+func (rc TestChecker) evaluate(ctx context.Context) (results []result, isDone bool) {
+	settings := rc.settings()
+	if rc.state == nil || !rc.state.isSingleFlight {
+		return rc.evaluateTests(ctx, settings)
+	}
+
+	return rc.state.flights.share(ctx, settings.tests, func(ctx context.Context) ([]result, bool) {
+		return rc.evaluateTests(ctx, settings)
+	})
+}
+
+func (rc TestChecker) evaluateTests(ctx context.Context, settings checkerSettings) (results []result, isDone bool) {
 	logging.L.DebugF("Will execute ready scripts")
+	br := rc.breakers()
+
 	wg := &sync.WaitGroup{}
-	wg.Add(len(rc.tests))
+	wg.Add(len(settings.tests))
 
 	resultChan := make(chan result)
 
-	for _, test := range rc.tests {
-		go rc.checkTest(test, wg, resultChan)
+	results = pendingResults(settings.tests)
+	g := newGate(settings.tests, results)
+	for i, test := range settings.tests {
+		if m, ok := rc.mute(test); ok {
+			go sendMuted(ctx, i, test, m, wg, resultChan)
+			continue
+		}
+		go settings.checkTest(ctx, i, test, g, br, wg, resultChan)
 	}
 
-	allDone := make(chan bool)
+	allDone := make(chan struct{})
 	go func() {
 		wg.Wait()
-		allDone <- true
+		close(allDone)
 	}()
 
-	errs := make([]string, 0, len(rc.tests))
 	for {
 		select {
 		case <-ctx.Done():
-			return false, errors.New("ready tests failed due to the context timeout")
+			return results, false
 		case res := <-resultChan:
-			if !res.isReady {
-				errs = append(errs, fmt.Sprintf("Readiness probe failed for %s: %v", res.test.Name, res.err))
+			if !res.isBlocked && res.mute == nil {
+				res = rc.applyThresholds(res)
 			}
+			g.complete(res)
 		case <-allDone:
-			if len(errs) == 0 {
-				return true, nil
-			}
-			return false, errors.New(strings.Join(errs, ", "))
+			return results, true
 		}
 	}
 }
 
-func (rc TestChecker) checkTest(test Test, wg *sync.WaitGroup, resultChan chan result) {
+func (rc TestChecker) applyThresholds(res result) result {
+	if rc.state == nil {
+		return res
+	}
+
+	return rc.state.thresholds.apply(res)
+}
+
+func (rc checkerSettings) checkTest(ctx context.Context, index int, test Test, g *gate, br *breakers, wg *sync.WaitGroup, resultChan chan result) {
 	defer wg.Done()
 
+	blockedErr, isCancelled := g.wait(ctx, index)
+	if isCancelled {
+		return
+	}
+	if blockedErr != nil {
+		logging.L.DebugF("%s is skipped: %v", test.Name, blockedErr)
+		sendResult(ctx, resultChan, result{index: index, test: test, isReady: false, isBlocked: true, err: blockedErr})
+		return
+	}
+
+	isTrial, err := br.allow(test)
+	if err != nil {
+		logging.L.DebugF("%s is not ready: %v", test.Name, err)
+		sendResult(ctx, resultChan, result{index: index, test: test, isReady: false, err: err})
+		return
+	}
+
+	maxRetries := rc.maxRetries
+	if isTrial {
+		maxRetries = 1
+	}
+
 	var errToGive error
-	for i := 0; i < rc.maxRetries; i++ {
+	for i := 0; i < maxRetries; i++ {
+		if ctx.Err() != nil || rc.acquire(ctx) {
+			logging.L.DebugF("Evaluation of %s is abandoned: %v", test.Name, ctx.Err())
+			if isTrial {
+				br.cancelTrial(test)
+			}
+			return
+		}
 		logging.L.DebugF("Will check if %s is ready, attempt %d", test.Name, i+1)
-		err := test.TestFunc()
+		err := test.run(ctx)
+		rc.release()
 		if err == nil {
 			logging.L.DebugF("%s is ready", test.Name)
-			resultChan <- result{test: test, isReady: true, err: nil}
+			br.record(test, true, nil)
+			sendResult(ctx, resultChan, result{index: index, test: test, isReady: true, err: nil})
 			return
 		}
 
 		errToGive = err
 
-		if rc.maxRetries > 1 {
+		if maxRetries > 1 {
 			rc.sleeper.Sleep(rc.sleepInterval)
 		}
 
 		logging.L.WarnF("%s is not ready: %v", test.Name, err)
 	}
 
-	resultChan <- result{test: test, isReady: false, err: errToGive}
+	if ctx.Err() != nil {
+		// the failure might be caused by the abandoned evaluation, so it's not counted by the circuit breaker
+		if isTrial {
+			br.cancelTrial(test)
+		}
+		return
+	}
+
+	br.record(test, false, errToGive)
+	sendResult(ctx, resultChan, result{index: index, test: test, isReady: false, err: errToGive})
+}
+
+// sendResult gives the result to the evaluation unless it's abandoned
+func sendResult(ctx context.Context, resultChan chan result, res result) {
+	select {
+	case resultChan <- res:
+	case <-ctx.Done():
+	}
 }
@@ -3,6 +3,7 @@ package ready
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -171,3 +172,91 @@ func TestWaitingTimeout(t *testing.T) {
 	assert.False(t, isReady)
 	assert.EqualError(t, err, "ready tests failed due to the context timeout")
 }
+
+func TestReconfigure(t *testing.T) {
+	inFlight := make(chan bool)
+	release := make(chan bool)
+	checker := NewTestChecker([]Test{
+		{
+			TestFunc: func() error {
+				inFlight <- true
+				<-release
+				return nil
+			},
+			Name: "slow",
+		},
+	},
+		1,
+		time.Millisecond,
+		sleep.NewSleeperMock(),
+	)
+
+	type readyResult struct {
+		isReady bool
+		err     error
+	}
+	resChan := make(chan readyResult)
+	go func() {
+		isReady, err := checker.IsReady(context.Background())
+		resChan <- readyResult{isReady: isReady, err: err}
+	}()
+
+	<-inFlight
+	checkerCopy := checker
+	checkerCopy.Reconfigure([]Test{
+		{
+			TestFunc: func() error {
+				return errors.New("some error")
+			},
+			Name: "failing",
+		},
+	}, 1, time.Millisecond)
+	close(release)
+
+	res := <-resChan
+	assert.True(t, res.isReady)
+	assert.NoError(t, res.err)
+
+	isReady, err := checker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "Readiness probe failed for failing: some error")
+}
+
+func TestReconfigureConcurrently(t *testing.T) {
+	checker := NewTestChecker([]Test{}, 1, time.Millisecond, sleep.NewSleeperMock())
+	tests := []Test{
+		{
+			TestFunc: func() error {
+				return nil
+			},
+			Name: "ok",
+		},
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			checker.Reconfigure(tests, i+1, time.Millisecond)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			isReady, err := checker.IsReady(context.Background())
+			assert.True(t, isReady)
+			assert.NoError(t, err)
+		}
+	}()
+	wg.Wait()
+}
+
+func TestZeroTestChecker(t *testing.T) {
+	checker := TestChecker{}
+	checker.Reconfigure([]Test{}, 1, time.Millisecond)
+
+	isReady, err := checker.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+}
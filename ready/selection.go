@@ -0,0 +1,100 @@
+package ready
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownTest is given when a Selection includes a test which doesn't exist
+var ErrUnknownTest = errors.New("unknown ready test")
+
+// Selection chooses tests of an evaluation, e.g. to run a single dependency check while debugging an incident
+type Selection struct {
+	// Include names of tests to evaluate, empty means all tests
+	Include []string
+	// Exclude names of tests to skip, unknown names are ignored
+	Exclude []string
+}
+
+// IsEmpty is true if the selection contains all tests
+func (s Selection) IsEmpty() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+// SelectiveChecker is implemented by checkers which can evaluate a selection of their tests, e.g. TestChecker
+type SelectiveChecker interface {
+	Checker
+	ReportFor(ctx context.Context, sel Selection) (Report, error)
+}
+
+// ReportFor evaluates the selected tests like Report, dependencies on tests which are not selected are ignored,
+// the error wraps ErrUnknownTest if an included test doesn't exist
+func (rc TestChecker) ReportFor(ctx context.Context, sel Selection) (Report, error) {
+	if sel.IsEmpty() {
+		return rc.Report(ctx), nil
+	}
+
+	settings := rc.settings()
+	tests, excluded, err := sel.apply(settings.tests)
+	if err != nil {
+		return Report{}, err
+	}
+	settings.tests = tests
+
+	results, isDone := rc.evaluateTests(ctx, settings)
+	report := rc.buildReport(results, isDone)
+	report.Excluded = excluded
+
+	return report, nil
+}
+
+// apply gives the selected tests without dependencies on not selected tests and names of excluded tests
+func (s Selection) apply(tests []Test) (selected []Test, excluded []string, err error) {
+	names := make(map[string]bool, len(tests))
+	for _, t := range tests {
+		names[t.Name] = true
+	}
+
+	included := make(map[string]bool, len(s.Include))
+	for _, name := range s.Include {
+		if !names[name] {
+			return nil, nil, fmt.Errorf("%w %s", ErrUnknownTest, name)
+		}
+		included[name] = true
+	}
+
+	isExcluded := make(map[string]bool, len(s.Exclude))
+	for _, name := range s.Exclude {
+		isExcluded[name] = true
+	}
+
+	isSelected := make(map[string]bool, len(tests))
+	selected = make([]Test, 0, len(tests))
+	for _, t := range tests {
+		if isExcluded[t.Name] {
+			excluded = append(excluded, t.Name)
+			continue
+		}
+		if len(included) > 0 && !included[t.Name] {
+			continue
+		}
+		isSelected[t.Name] = true
+		selected = append(selected, t)
+	}
+
+	for i, t := range selected {
+		if len(t.DependsOn) == 0 {
+			continue
+		}
+		deps := make([]string, 0, len(t.DependsOn))
+		for _, name := range t.DependsOn {
+			if isSelected[name] {
+				deps = append(deps, name)
+			}
+		}
+		selected[i].DependsOn = deps
+	}
+
+	return selected, excluded, nil
+}
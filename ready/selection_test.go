@@ -0,0 +1,52 @@
+package ready
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportFor(t *testing.T) {
+	executed := make(chan string, 10)
+	testFunc := func(name string, err error) func() error {
+		return func() error {
+			executed <- name
+			return err
+		}
+	}
+
+	checker := NewTestChecker([]Test{
+		{Name: "db-port", TestFunc: testFunc("db-port", errors.New("connection refused"))},
+		{Name: "db-table", TestFunc: testFunc("db-table", nil), DependsOn: []string{"db-port"}},
+		{Name: "cache", TestFunc: testFunc("cache", nil)},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	report, err := checker.ReportFor(context.Background(), Selection{Include: []string{"db-table"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Report{IsReady: true, Tests: []TestReport{{Name: "db-table", IsReady: true}}}, report)
+	assert.Equal(t, "db-table", <-executed)
+	assert.Len(t, executed, 0)
+
+	report, err = checker.ReportFor(context.Background(), Selection{Exclude: []string{"cache", "unknown"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Report{
+		IsReady: false,
+		Tests: []TestReport{
+			{Name: "db-port", IsReady: false, Error: "connection refused"},
+			{Name: "db-table", IsReady: false, Error: "blocked by db-port", IsBlocked: true},
+		},
+		Excluded: []string{"cache"},
+	}, report)
+
+	_, err = checker.ReportFor(context.Background(), Selection{Include: []string{"kafka"}})
+	assert.EqualError(t, err, "unknown ready test kafka")
+	assert.True(t, errors.Is(err, ErrUnknownTest))
+
+	report, err = checker.ReportFor(context.Background(), Selection{})
+	assert.NoError(t, err)
+	assert.Len(t, report.Tests, 3)
+}
@@ -0,0 +1,77 @@
+package ready
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxErrBodyLen = 256
+
+// NewTCPTest gives Test which succeeds if a tcp connection to the address can be established within the timeout
+func NewTCPTest(name, address string, timeout time.Duration) Test {
+	checkTCP := func(ctx context.Context) error {
+		dialer := net.Dialer{Timeout: timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+
+	return Test{
+		TestFunc: func() error {
+			return checkTCP(context.Background())
+		},
+		ContextFunc: checkTCP,
+		Name:        name,
+	}
+}
+
+// NewHTTPTest gives Test which succeeds if a GET request to the url is answered with a 2xx status code within the timeout
+func NewHTTPTest(name, url string, timeout time.Duration) Test {
+	return Test{
+		TestFunc: func() error {
+			return CheckHTTP(context.Background(), url, timeout)
+		},
+		ContextFunc: func(ctx context.Context) error {
+			return CheckHTTP(ctx, url, timeout)
+		},
+		Name: name,
+	}
+}
+
+// CheckHTTP sends a GET request to the url and fails if it's not answered with a 2xx status code within the timeout,
+// a short part of the response body is added to the error
+func CheckHTTP(ctx context.Context, url string, timeout time.Duration) error {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrBodyLen))
+	reason := strings.TrimSpace(string(body))
+	if reason == "" {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, reason)
+}
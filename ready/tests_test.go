@@ -0,0 +1,84 @@
+package ready
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPTest(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	test := NewTCPTest("db", lis.Addr().String(), time.Second)
+	assert.Equal(t, "db", test.Name)
+	assert.NoError(t, test.TestFunc())
+
+	lis.Close()
+	assert.Error(t, test.TestFunc())
+}
+
+func TestHTTPTest(t *testing.T) {
+	isReady := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isReady {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("starting\n"))
+		}
+	}))
+	defer srv.Close()
+
+	test := NewHTTPTest("api", srv.URL, time.Second)
+	assert.Equal(t, "api", test.Name)
+	assert.EqualError(t, test.TestFunc(), "unexpected status code 503: starting")
+
+	isReady = true
+	assert.NoError(t, test.TestFunc())
+}
+
+func TestContextFuncIsCancelled(t *testing.T) {
+	cancelled := make(chan error, 1)
+	checker := NewTestChecker([]Test{
+		{
+			TestFunc: func() error {
+				return errors.New("TestFunc should not be used")
+			},
+			ContextFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				cancelled <- ctx.Err()
+				return ctx.Err()
+			},
+			Name: "slow",
+		},
+	}, 3, time.Millisecond, sleep.NewSleeperMock())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	isReady, err := checker.IsReady(ctx)
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "ready tests failed due to the context timeout")
+	assert.Equal(t, context.DeadlineExceeded, <-cancelled)
+
+	time.Sleep(time.Millisecond * 10)
+	assert.Len(t, cancelled, 0, "abandoned evaluation should not be retried")
+}
+
+func TestTCPTestIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewTCPTest("db", "127.0.0.1:1", time.Second).ContextFunc(ctx)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
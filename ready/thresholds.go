@@ -0,0 +1,94 @@
+package ready
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+// thresholds keeps consecutive results of tests with failure or success thresholds between evaluations
+type thresholds struct {
+	lock   sync.Mutex
+	states map[string]*testState
+}
+
+type testState struct {
+	isReady     bool
+	consecutive int
+	lastErr     error
+}
+
+func (t Test) hasThresholds() bool {
+	return t.FailureThreshold > 1 || t.SuccessThreshold > 1
+}
+
+// apply gives the reported result of a test, the first result of a test is reported as is,
+// later the reported state changes only after FailureThreshold consecutive failures or SuccessThreshold consecutive successes,
+// only isReady and err of the result are replaced
+func (th *thresholds) apply(res result) result {
+	if !res.test.hasThresholds() {
+		return res
+	}
+
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	if th.states == nil {
+		th.states = map[string]*testState{}
+	}
+
+	st, ok := th.states[res.test.Name]
+	if !ok {
+		th.states[res.test.Name] = &testState{isReady: res.isReady, lastErr: res.err}
+		return res
+	}
+
+	if !res.isReady {
+		st.lastErr = res.err
+	}
+
+	if res.isReady == st.isReady {
+		st.consecutive = 0
+		return res
+	}
+
+	st.consecutive++
+	threshold := res.test.SuccessThreshold
+	if !res.isReady {
+		threshold = res.test.FailureThreshold
+	}
+
+	if st.consecutive >= threshold {
+		st.isReady = res.isReady
+		st.consecutive = 0
+		return res
+	}
+
+	if res.isReady {
+		res.isReady, res.err = false, fmt.Errorf("%v, recovering with %d of %d required successful checks", st.lastErr, st.consecutive, threshold)
+		return res
+	}
+
+	logging.L.WarnF("%s failure is tolerated, %d of %d failures: %v", res.test.Name, st.consecutive, threshold, res.err)
+	res.isReady, res.err = true, nil
+
+	return res
+}
+
+// retain forgets states of tests which are not in the list anymore
+func (th *thresholds) retain(tests []Test) {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	names := make(map[string]bool, len(tests))
+	for _, t := range tests {
+		names[t.Name] = true
+	}
+
+	for name := range th.states {
+		if !names[name] {
+			delete(th.states, name)
+		}
+	}
+}
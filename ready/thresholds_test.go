@@ -0,0 +1,146 @@
+package ready
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureAndSuccessThresholds(t *testing.T) {
+	results := []error{
+		nil,
+		errors.New("err1"),
+		errors.New("err2"),
+		nil,
+		errors.New("err3"),
+		errors.New("err4"),
+		errors.New("err5"),
+		nil,
+		nil,
+	}
+	evaluation := 0
+
+	checker := NewTestChecker([]Test{
+		{
+			TestFunc: func() error {
+				return results[evaluation]
+			},
+			Name:             "db",
+			FailureThreshold: 3,
+			SuccessThreshold: 2,
+		},
+	},
+		1,
+		time.Millisecond,
+		sleep.NewSleeperMock(),
+	)
+
+	expectedErrs := []string{
+		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"Readiness probe failed for db: err5",
+		"Readiness probe failed for db: err5, recovering with 1 of 2 required successful checks",
+		"",
+	}
+
+	for i, expectedErr := range expectedErrs {
+		evaluation = i
+		isReady, err := checker.IsReady(context.Background())
+		if expectedErr == "" {
+			assert.True(t, isReady, "evaluation %d", i)
+			assert.NoError(t, err, "evaluation %d", i)
+			continue
+		}
+		assert.False(t, isReady, "evaluation %d", i)
+		assert.EqualError(t, err, expectedErr, "evaluation %d", i)
+	}
+}
+
+func TestFirstResultIgnoresThresholds(t *testing.T) {
+	checker := NewTestChecker([]Test{
+		{
+			TestFunc: func() error {
+				return errors.New("db is down")
+			},
+			Name:             "db",
+			FailureThreshold: 3,
+		},
+	},
+		1,
+		time.Millisecond,
+		sleep.NewSleeperMock(),
+	)
+
+	isReady, err := checker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "Readiness probe failed for db: db is down")
+}
+
+func TestReconfigureForgetsThresholdStates(t *testing.T) {
+	isFailing := false
+	test := Test{
+		TestFunc: func() error {
+			if isFailing {
+				return errors.New("db is down")
+			}
+			return nil
+		},
+		Name:             "db",
+		FailureThreshold: 2,
+	}
+	checker := NewTestChecker([]Test{test}, 1, time.Millisecond, sleep.NewSleeperMock())
+
+	isReady, _ := checker.IsReady(context.Background())
+	assert.True(t, isReady)
+
+	checker.Reconfigure([]Test{}, 1, time.Millisecond)
+	checker.Reconfigure([]Test{test}, 1, time.Millisecond)
+
+	isFailing = true
+	isReady, err := checker.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "Readiness probe failed for db: db is down")
+}
+
+func TestThresholdsKeepTestOrder(t *testing.T) {
+	results := []error{nil, errors.New("kafka is down"), errors.New("kafka is down"), nil}
+	evaluation := 0
+
+	checker := NewTestChecker([]Test{
+		{TestFunc: func() error { return nil }, Name: "db"},
+		{
+			TestFunc: func() error {
+				return results[evaluation]
+			},
+			Name:             "kafka",
+			FailureThreshold: 2,
+			SuccessThreshold: 2,
+		},
+	},
+		1,
+		time.Millisecond,
+		sleep.NewSleeperMock(),
+	)
+
+	expectedTests := [][]TestReport{
+		{{Name: "db", IsReady: true}, {Name: "kafka", IsReady: true}},
+		{{Name: "db", IsReady: true}, {Name: "kafka", IsReady: true}},
+		{{Name: "db", IsReady: true}, {Name: "kafka", Error: "kafka is down"}},
+		{{Name: "db", IsReady: true}, {Name: "kafka", Error: "kafka is down, recovering with 1 of 2 required successful checks"}},
+	}
+
+	for i, expected := range expectedTests {
+		evaluation = i
+		report, err := checker.ReportFor(context.Background(), Selection{})
+		assert.NoError(t, err, "evaluation %d", i)
+		assert.Equal(t, expected, report.Tests, "evaluation %d", i)
+	}
+}
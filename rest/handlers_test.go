@@ -0,0 +1,475 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/auth"
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/breathbath/healthReadyChecks/ready"
+	"github.com/breathbath/healthReadyChecks/sleep"
+	"github.com/stretchr/testify/assert"
+)
+
+type healthCheckerMock struct {
+	isHealthy bool
+	reason    string
+}
+
+// IsHealthy health.Checker implementation
+func (hcm healthCheckerMock) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	return hcm.isHealthy, hcm.reason
+}
+
+// SubscribeToUnhealthyChange health.Checker implementation
+func (hcm healthCheckerMock) SubscribeToUnhealthyChange(sf func(reason string)) {}
+
+type statusHealthCheckerMock struct {
+	healthCheckerMock
+	status health.Status
+}
+
+// Status health.StatusProvider implementation
+func (shcm statusHealthCheckerMock) Status() health.Status {
+	return shcm.status
+}
+
+func TestHealthHandlerJSON(t *testing.T) {
+	hc := statusHealthCheckerMock{
+		healthCheckerMock: healthCheckerMock{isHealthy: false, reason: "too many errors"},
+		status: health.Status{
+			State:      health.StateUnhealthy,
+			Since:      time.Unix(100, 0).UTC(),
+			Reason:     "too many errors",
+			ErrorCount: 3,
+			Threshold:  2,
+			Samples:    []health.ErrorSample{{Message: "conn refused", Category: "db", Count: 1}},
+		},
+	}
+	handler := NewHealthHandler(hc)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/healthz?format=json", http.NoBody),
+		func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody)
+			r.Header.Set("Accept", "application/json")
+			return r
+		}(),
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		st := health.Status{}
+		err := json.Unmarshal(rec.Body.Bytes(), &st)
+		assert.NoError(t, err)
+		assert.Equal(t, hc.status, st)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "too many errors", rec.Body.String())
+}
+
+func TestHealthHandlerJSONWithoutStatusProvider(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHealthHandler(healthCheckerMock{isHealthy: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz?format=json", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "", rec.Body.String())
+}
+
+type deadlineReadyChecker struct {
+	deadline    time.Time
+	hasDeadline bool
+}
+
+// IsReady ready.Checker implementation
+func (drc *deadlineReadyChecker) IsReady(ctx context.Context) (isReady bool, err error) {
+	drc.deadline, drc.hasDeadline = ctx.Deadline()
+
+	return true, nil
+}
+
+func TestReadyHandlerTimeout(t *testing.T) {
+	testCases := []struct {
+		name            string
+		maxTimeout      time.Duration
+		headers         map[string]string
+		expectedTimeout time.Duration
+	}{
+		{name: "max timeout", maxTimeout: time.Second * 5, expectedTimeout: time.Second * 5},
+		{name: "no timeout", maxTimeout: 0},
+		{name: "ready header", maxTimeout: time.Second * 5, headers: map[string]string{ReadyTimeoutHeader: "2.5"}, expectedTimeout: time.Millisecond * 2500},
+		{name: "prometheus header", maxTimeout: time.Second * 5, headers: map[string]string{PrometheusTimeoutHeader: "1"}, expectedTimeout: time.Second},
+		{name: "header above max", maxTimeout: time.Second * 5, headers: map[string]string{ReadyTimeoutHeader: "10"}, expectedTimeout: time.Second * 5},
+		{name: "header without max", maxTimeout: 0, headers: map[string]string{ReadyTimeoutHeader: "3"}, expectedTimeout: time.Second * 3},
+		{name: "invalid header", maxTimeout: time.Second * 5, headers: map[string]string{ReadyTimeoutHeader: "soon"}, expectedTimeout: time.Second * 5},
+		{name: "infinite header", maxTimeout: time.Second * 5, headers: map[string]string{ReadyTimeoutHeader: "Inf"}, expectedTimeout: time.Second * 5},
+		{name: "not a number header", maxTimeout: time.Second * 5, headers: map[string]string{ReadyTimeoutHeader: "NaN"}, expectedTimeout: time.Second * 5},
+		{name: "huge header", maxTimeout: time.Second * 5, headers: map[string]string{ReadyTimeoutHeader: "1e10"}, expectedTimeout: time.Second * 5},
+		{name: "overflowing header", maxTimeout: time.Second * 5, headers: map[string]string{PrometheusTimeoutHeader: "1e300"}, expectedTimeout: time.Second * 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := &deadlineReadyChecker{}
+			req := httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			start := time.Now()
+			rec := httptest.NewRecorder()
+			NewReadyHandler(tc.maxTimeout, checker).ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			if tc.expectedTimeout == 0 {
+				assert.False(t, checker.hasDeadline)
+				return
+			}
+			assert.True(t, checker.hasDeadline)
+			assert.WithinDuration(t, start.Add(tc.expectedTimeout), checker.deadline, time.Millisecond*100)
+		})
+	}
+}
+
+func TestReadyHandlerRequestCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	NewReadyHandler(time.Second, readyFunc(func(ctx context.Context) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	})).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "context canceled", rec.Body.String())
+}
+
+type readyFunc func(ctx context.Context) (bool, error)
+
+// IsReady ready.Checker implementation
+func (rf readyFunc) IsReady(ctx context.Context) (isReady bool, err error) {
+	return rf(ctx)
+}
+
+func newSelectiveReadyChecker() ready.Checker {
+	return ready.NewTestChecker([]ready.Test{
+		{TestFunc: func() error { return nil }, Name: "db"},
+		{TestFunc: func() error { return errors.New("broker is down") }, Name: "kafka"},
+		{TestFunc: func() error { return nil }, Name: "cache"},
+	}, 1, time.Millisecond, sleep.NewSleeperMock())
+}
+
+func TestReadyHandlerSelection(t *testing.T) {
+	handler := NewReadyHandler(time.Second, newSelectiveReadyChecker())
+
+	testCases := []struct {
+		name         string
+		target       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "all tests",
+			target:       "/readyz",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "Readiness probe failed for kafka: broker is down",
+		},
+		{
+			name:         "included test",
+			target:       "/readyz?check=db",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "excluded failed test",
+			target:       "/readyz?exclude=kafka",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "included failed test",
+			target:       "/readyz?check=db&check=kafka",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "Readiness probe failed for kafka: broker is down",
+		},
+		{
+			name:         "unknown test",
+			target:       "/readyz?check=redis",
+			expectedCode: http.StatusNotFound,
+			expectedBody: "unknown ready test redis",
+		},
+		{
+			name:         "verbose",
+			target:       "/readyz?verbose&exclude=cache&exclude=redis",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "[+]db ok\n" +
+				"[-]kafka failed: reason withheld\n" +
+				"[+]cache excluded: ok\n" +
+				"warn: some health checks cannot be excluded: no matches for \"redis\"\n" +
+				"readyz check failed\n",
+		},
+		{
+			name:         "verbose passed",
+			target:       "/readyz?verbose&check=db",
+			expectedCode: http.StatusOK,
+			expectedBody: "[+]db ok\nreadyz check passed\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestReadyHandlerSelectionJSON(t *testing.T) {
+	handler := NewReadyHandler(time.Second, newSelectiveReadyChecker())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?format=json&exclude=kafka", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	report := ready.Report{}
+	err := json.Unmarshal(rec.Body.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.True(t, report.IsReady)
+	assert.Equal(t, []string{"kafka"}, report.Excluded)
+	assert.Len(t, report.Tests, 2)
+}
+
+func TestReadyHandlerCheckFromPath(t *testing.T) {
+	handler := withCheckFromPath("/readyz/", NewReadyHandler(time.Second, newSelectiveReadyChecker()))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz/db", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz/kafka?verbose", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "[-]kafka failed: reason withheld\nreadyz check failed\n", rec.Body.String())
+}
+
+func TestReadyHandlerSelectionWithoutSelectiveChecker(t *testing.T) {
+	handler := NewReadyHandler(time.Second, readyFunc(func(ctx context.Context) (bool, error) {
+		return true, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?check=redis", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestKubeHealthHandler(t *testing.T) {
+	handler := NewKubeHealthHandler("livez", health.CombineComponents(
+		health.Component{Name: "storage", Checker: healthCheckerMock{isHealthy: true}},
+		health.Component{Name: "heartbeats", Checker: healthCheckerMock{isHealthy: false, reason: "worker is stalled"}},
+	))
+
+	testCases := []struct {
+		name         string
+		target       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "failed",
+			target:       "/livez",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "[+]storage ok\n[-]heartbeats failed: reason withheld\nlivez check failed\n",
+		},
+		{
+			name:         "excluded",
+			target:       "/livez?exclude=heartbeats",
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		{
+			name:         "excluded verbose",
+			target:       "/livez?exclude=heartbeats&exclude=etcd&verbose",
+			expectedCode: http.StatusOK,
+			expectedBody: "[+]storage ok\n[+]heartbeats excluded: ok\n" +
+				"warn: some health checks cannot be excluded: no matches for \"etcd\"\nlivez check passed\n",
+		},
+		{
+			name:         "single check",
+			target:       "/livez/storage",
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		{
+			name:         "single failed check",
+			target:       "/livez/heartbeats",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "internal server error: worker is stalled\n",
+		},
+		{
+			name:         "unknown check",
+			target:       "/livez/etcd",
+			expectedCode: http.StatusNotFound,
+			expectedBody: "404 page not found\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestKubeHealthHandlerWithoutComponents(t *testing.T) {
+	handler := NewKubeHealthHandler("livez", healthCheckerMock{isHealthy: true})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez?verbose", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[+]health ok\nlivez check passed\n", rec.Body.String())
+}
+
+func TestKubeReadyHandler(t *testing.T) {
+	handler := NewKubeReadyHandler("readyz", time.Second, newSelectiveReadyChecker())
+
+	testCases := []struct {
+		name         string
+		target       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "failed",
+			target:       "/readyz",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "[+]db ok\n[-]kafka failed: reason withheld\n[+]cache ok\nreadyz check failed\n",
+		},
+		{
+			name:         "excluded",
+			target:       "/readyz?exclude=kafka",
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		{
+			name:         "single check",
+			target:       "/readyz/db",
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		{
+			name:         "single failed check",
+			target:       "/readyz/kafka",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "internal server error: broker is down\n",
+		},
+		{
+			name:         "unknown check",
+			target:       "/readyz/redis",
+			expectedCode: http.StatusNotFound,
+			expectedBody: "404 page not found\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestKubeReadyHandlerWithoutSelectiveChecker(t *testing.T) {
+	handler := NewKubeReadyHandler("readyz", time.Second, readyFunc(func(ctx context.Context) (bool, error) {
+		return false, errors.New("db is down")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "[-]ready failed: reason withheld\nreadyz check failed\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?exclude=ready&verbose", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[+]ready excluded: ok\nreadyz check passed\n", rec.Body.String())
+}
+
+type overriddenReadyChecker struct {
+	readyFunc
+}
+
+// ReportFor ready.SelectiveChecker implementation
+func (orc overriddenReadyChecker) ReportFor(ctx context.Context, sel ready.Selection) (ready.Report, error) {
+	return ready.Report{
+		Tests:    []ready.TestReport{{Name: "db", IsReady: true}},
+		Override: "forced not ready: draining",
+	}, nil
+}
+
+func TestReadyHandlersWithOverride(t *testing.T) {
+	checker := overriddenReadyChecker{}
+
+	rec := httptest.NewRecorder()
+	NewKubeReadyHandler("readyz", time.Second, checker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "[-]override failed: reason withheld\nreadyz check failed\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	NewReadyHandler(time.Second, checker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?check=db", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "forced not ready: draining", rec.Body.String())
+}
+
+type failingReportChecker struct {
+	readyFunc
+}
+
+// ReportFor ready.SelectiveChecker implementation
+func (frc failingReportChecker) ReportFor(ctx context.Context, sel ready.Selection) (ready.Report, error) {
+	return ready.Report{}, errors.New("dial tcp 10.0.0.5:5432: connection refused")
+}
+
+func TestReadyHandlersWithholdErrors(t *testing.T) {
+	handlers := []http.Handler{
+		NewReadyHandler(time.Second, failingReportChecker{}),
+		NewKubeReadyHandler("readyz", time.Second, failingReportChecker{}),
+	}
+
+	for _, handler := range handlers {
+		req := httptest.NewRequest(http.MethodGet, "/readyz?verbose", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(auth.WithholdDetails(req.Context())))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "Internal Server Error", rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?verbose", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "dial tcp 10.0.0.5:5432: connection refused", rec.Body.String())
+	}
+}
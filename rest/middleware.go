@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+// CategoryServerError category of errors created from 5xx responses
+const CategoryServerError = "http_server_error"
+
+type middlewareConfig struct {
+	isCountingServerErrors bool
+	recorder               health.OutcomeRecorder
+}
+
+// MiddlewareOption configures the middleware of NewErrorsMiddleware
+type MiddlewareOption func(mc *middlewareConfig)
+
+// WithServerErrors sends an error for every 5xx response additionally to panics
+func WithServerErrors() MiddlewareOption {
+	return func(mc *middlewareConfig) {
+		mc.isCountingServerErrors = true
+	}
+}
+
+// WithOutcomeRecorder records every request as a failure if an error is sent for it and as a success otherwise,
+// e.g. for health.RatioListener
+func WithOutcomeRecorder(recorder health.OutcomeRecorder) MiddlewareOption {
+	return func(mc *middlewareConfig) {
+		mc.recorder = recorder
+	}
+}
+
+func (mc middlewareConfig) send(sender errs.Sender, ep errs.ErrPayload) {
+	sender.SendPayload(ep)
+	if mc.recorder != nil {
+		mc.recorder.RecordFailure(ep.Err)
+	}
+}
+
+func (mc middlewareConfig) recordSuccess() {
+	if mc.recorder != nil {
+		mc.recorder.RecordSuccess()
+	}
+}
+
+// NewErrorsMiddleware gives the middleware which recovers panics of the next handler, responds with 500 and sends them to the sender
+// as *errs.PanicError with errs.CategoryPanic, http.ErrAbortHandler panics are deliberate aborts, e.g. of httputil.ReverseProxy
+// on client disconnects, they are re-panicked without sending them or recording an outcome
+func NewErrorsMiddleware(sender errs.Sender, opts ...MiddlewareOption) func(next http.Handler) http.Handler {
+	mc := middlewareConfig{}
+	for _, opt := range opts {
+		opt(&mc)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w}
+
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					if mc.isCountingServerErrors && sw.statusCode >= http.StatusInternalServerError {
+						mc.send(sender, errs.NewErrPayload(
+							fmt.Errorf("%s %s responded with status %d", r.Method, r.URL.Path, sw.statusCode),
+							CategoryServerError,
+						))
+						return
+					}
+					mc.recordSuccess()
+					return
+				}
+
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				mc.send(sender, errs.NewErrPayload(errs.NewPanicError(rec), errs.CategoryPanic))
+
+				logging.L.ErrorF("Recovered panic in %s %s: %v", r.Method, r.URL.Path, rec)
+				if !sw.isHeaderWritten {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
+
+// statusWriter remembers the response status code
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode      int
+	isHeaderWritten bool
+}
+
+// WriteHeader http.ResponseWriter implementation
+func (sw *statusWriter) WriteHeader(statusCode int) {
+	if !sw.isHeaderWritten {
+		sw.statusCode = statusCode
+		sw.isHeaderWritten = true
+	}
+	sw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write http.ResponseWriter implementation
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if !sw.isHeaderWritten {
+		sw.statusCode = http.StatusOK
+		sw.isHeaderWritten = true
+	}
+
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush http.Flusher implementation, e.g. for streaming responses
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		if !sw.isHeaderWritten {
+			sw.statusCode = http.StatusOK
+			sw.isHeaderWritten = true
+		}
+		f.Flush()
+	}
+}
+
+// Hijack http.Hijacker implementation, e.g. for websocket upgrades, the response is treated as 101 Switching Protocols
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := sw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T doesn't implement http.Hijacker", sw.ResponseWriter)
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil && !sw.isHeaderWritten {
+		sw.statusCode = http.StatusSwitchingProtocols
+		sw.isHeaderWritten = true
+	}
+
+	return conn, rw, err
+}
+
+// Push http.Pusher implementation for HTTP/2 server push
+func (sw *statusWriter) Push(target string, opts *http.PushOptions) error {
+	p, ok := sw.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+
+	return p.Push(target, opts)
+}
+
+// Unwrap gives the wrapped http.ResponseWriter to http.ResponseController
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/breathbath/healthReadyChecks/errs"
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorsMiddlewareRecoversPanics(t *testing.T) {
+	errStream := errs.NewErrStream(10)
+	handler := NewErrorsMiddleware(errStream)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler is broken")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", http.NoBody))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Len(t, errStream, 1)
+	ep := <-errStream
+	assert.Equal(t, errs.CategoryPanic, ep.Category)
+	assert.EqualError(t, ep.Err, "panic: handler is broken")
+
+	pe := &errs.PanicError{}
+	assert.True(t, errors.As(ep.Err, &pe))
+	assert.Equal(t, "handler is broken", pe.Value)
+	assert.NotEmpty(t, pe.Stack)
+}
+
+type outcomesCounter struct {
+	successes int
+	failures  int
+}
+
+func (oc *outcomesCounter) RecordSuccess() {
+	oc.successes++
+}
+
+func (oc *outcomesCounter) RecordFailure(err error) {
+	oc.failures++
+}
+
+func TestErrorsMiddlewareRepanicsAbortHandler(t *testing.T) {
+	errStream := errs.NewErrStream(10)
+	counter := &outcomesCounter{}
+	handler := NewErrorsMiddleware(errStream, WithOutcomeRecorder(counter))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	})
+	assert.Len(t, errStream, 0)
+	assert.Equal(t, &outcomesCounter{}, counter)
+}
+
+func TestErrorsMiddlewareServerErrors(t *testing.T) {
+	statusCode := http.StatusBadGateway
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	})
+
+	errStream := errs.NewErrStream(10)
+	NewErrorsMiddleware(errStream)(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", http.NoBody))
+	assert.Len(t, errStream, 0, "5xx responses should be ignored without the option")
+
+	handler := NewErrorsMiddleware(errStream, WithServerErrors())(next)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", http.NoBody))
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Len(t, errStream, 1)
+	ep := <-errStream
+	assert.Equal(t, CategoryServerError, ep.Category)
+	assert.EqualError(t, ep.Err, "POST /orders responded with status 502")
+
+	statusCode = http.StatusNotFound
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", http.NoBody))
+	assert.Len(t, errStream, 0)
+}
+
+func TestErrorsMiddlewareOutcomeRecorder(t *testing.T) {
+	statusCode := http.StatusOK
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	})
+
+	rl := health.NewRatioListener(health.RatioOptions{MaxFailureRatio: 0.5, MinRequests: 2})
+	handler := NewErrorsMiddleware(errs.NewErrStream(10), WithServerErrors(), WithOutcomeRecorder(rl))(next)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	statusCode = http.StatusServiceUnavailable
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	isHealthy, _ := rl.IsHealthy()
+	assert.True(t, isHealthy)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	isHealthy, reason := rl.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, reason, "Failure ratio 66.7% of 3 operations")
+	assert.Contains(t, reason, "last error: GET / responded with status 503")
+}
+
+func TestErrorsMiddlewareKeepsResponseWriterInterfaces(t *testing.T) {
+	errStream := errs.NewErrStream(10)
+	handler := NewErrorsMiddleware(errStream, WithServerErrors())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			_, _ = w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			return
+		}
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		_ = rw.Flush()
+	}))
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stream")
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "chunk", string(body))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/ws", http.NoBody)
+	assert.NoError(t, err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	resp, err = http.DefaultClient.Do(req)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+
+	assert.Len(t, errStream, 0)
+}
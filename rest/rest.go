@@ -2,19 +2,35 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/breathbath/healthReadyChecks/auth"
 	"github.com/breathbath/healthReadyChecks/health"
 	"github.com/breathbath/healthReadyChecks/logging"
 	"github.com/breathbath/healthReadyChecks/ready"
-	"github.com/gorilla/mux"
 )
 
 const portToUse = 9244
 
+// maxDurationSeconds is the largest timeout in seconds which fits into time.Duration
+var maxDurationSeconds = time.Duration(math.MaxInt64).Seconds()
+
+const (
+	// ReadyTimeoutHeader request header with the readiness timeout in seconds, e.g. "2.5"
+	ReadyTimeoutHeader = "X-Ready-Timeout-Seconds"
+	// PrometheusTimeoutHeader timeout header of Prometheus scrapes which is honored as well
+	PrometheusTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+)
+
 // Server wraps health/ready http server implementation
 type Server struct {
 	readyChecker  ready.Checker
@@ -22,6 +38,47 @@ type Server struct {
 	healthChecker health.Checker
 	isWithReady   bool
 	isWithHealth  bool
+	isKubeFormat  bool
+	pathPrefix    string
+	paths         Paths
+	host          string
+	started       chan<- net.Addr
+	auths         []pathAuth
+	routes        []route
+}
+
+type pathAuth struct {
+	paths         []string
+	authenticator auth.Authenticator
+}
+
+// Paths of health and ready endpoints, empty paths fall back to defaults
+type Paths struct {
+	// Health defaults to /healthz
+	Health string
+	// Live defaults to /livez
+	Live string
+	// Ready defaults to /readyz
+	Ready string
+}
+
+func (p Paths) withDefaults() Paths {
+	if p.Health == "" {
+		p.Health = "/healthz"
+	}
+	if p.Live == "" {
+		p.Live = "/livez"
+	}
+	if p.Ready == "" {
+		p.Ready = "/readyz"
+	}
+
+	return p
+}
+
+type route struct {
+	path    string
+	handler http.Handler
 }
 
 // WithHealth returns Server with health functionality
@@ -41,52 +98,252 @@ func WithReady(s Server, readyChecker ready.Checker, readyTimeout time.Duration)
 	return s
 }
 
-// Start starts health or/and ready server if they were initialized, if not returns an error
+// WithKubeFormat returns Server which responds on /readyz in the format of the Kubernetes API server, see NewKubeReadyHandler
+func WithKubeFormat(s Server) Server {
+	s.isKubeFormat = true
+
+	return s
+}
+
+// WithPathPrefix returns Server which serves all endpoints and handlers under the prefix, e.g. /internal/readyz for "/internal"
+func WithPathPrefix(s Server, prefix string) Server {
+	s.pathPrefix = strings.TrimSuffix(prefix, "/")
+
+	return s
+}
+
+// WithPaths returns Server which serves health and ready endpoints at the paths, e.g. /ready instead of /readyz
+func WithPaths(s Server, paths Paths) Server {
+	s.paths = paths
+
+	return s
+}
+
+// WithHost returns Server which listens only on the host in Start, e.g. 127.0.0.1, empty host means all interfaces
+func WithHost(s Server, host string) Server {
+	s.host = host
+
+	return s
+}
+
+// WithStarted returns Server which sends the address it listens on to the channel once it is listening,
+// e.g. to know the port chosen for port 0, the channel is not closed
+func WithStarted(s Server, started chan<- net.Addr) Server {
+	s.started = started
+
+	return s
+}
+
+// WithAuth returns Server which gives details only to callers allowed by the authenticator at the paths, all paths if none are given,
+// e.g. "/readyz" or a path of WithHandler. Health, live and ready endpoints respond to other callers with the status only,
+// handlers of WithHandler respond with 401. Paths are given without the prefix of WithPathPrefix, later calls take precedence
+func WithAuth(s Server, authenticator auth.Authenticator, paths ...string) Server {
+	auths := make([]pathAuth, len(s.auths), len(s.auths)+1)
+	copy(auths, s.auths)
+	s.auths = append(auths, pathAuth{paths: paths, authenticator: authenticator})
+
+	return s
+}
+
+// authenticator gives the authenticator of the path, nil if the path is not protected
+func (s Server) authenticator(p string) auth.Authenticator {
+	for i := len(s.auths) - 1; i >= 0; i-- {
+		pa := s.auths[i]
+		if len(pa.paths) == 0 {
+			return pa.authenticator
+		}
+		for _, authPath := range pa.paths {
+			if authPath == p {
+				return pa.authenticator
+			}
+		}
+	}
+
+	return nil
+}
+
+// withDetailsAuth withholds details of the handler of the path from callers not allowed by its authenticator
+func (s Server) withDetailsAuth(p string, handler http.Handler) http.Handler {
+	a := s.authenticator(p)
+	if a == nil {
+		return handler
+	}
+
+	return auth.NewMiddleware(a)(handler)
+}
+
+// WithHandler returns Server which additionally serves the handler at the path, e.g. details of health or ready checks
+func WithHandler(s Server, path string, handler http.Handler) Server {
+	routes := make([]route, len(s.routes), len(s.routes)+1)
+	copy(routes, s.routes)
+	s.routes = append(routes, route{path: path, handler: handler})
+
+	return s
+}
+
+// Validate checks that every path of health and ready endpoints and handlers of the server is served only once
+func (s Server) Validate() error {
+	isRegistered := map[string]bool{}
+	for _, rt := range s.endpoints() {
+		if isRegistered[rt.path] {
+			return fmt.Errorf("path %s is registered more than once", rt.path)
+		}
+		isRegistered[rt.path] = true
+	}
+
+	return nil
+}
+
+// Handler gives http.Handler serving health and ready endpoints and handlers of the server, e.g. to mount it into another router,
+// it panics like http.ServeMux if a path is registered more than once, use Validate to check the server beforehand
+func (s Server) Handler() http.Handler {
+	if s.isWithHealth {
+		logging.L.InfoF("Will start health listener with healthz and livez api")
+	}
+	if s.isWithReady {
+		logging.L.InfoF("Will start ready listener with readyz api")
+	}
+
+	router := http.NewServeMux()
+	for _, rt := range s.endpoints() {
+		router.Handle(rt.path, rt.handler)
+	}
+
+	return router
+}
+
+// endpoints gives health and ready endpoints and handlers of the server with the path prefix
+func (s Server) endpoints() []route {
+	paths := s.paths.withDefaults()
+	routes := make([]route, 0, len(s.routes)+4)
+
+	if s.isWithHealth {
+		liveHandler := s.withDetailsAuth(paths.Live, NewKubeHealthHandler(path.Base(paths.Live), s.healthChecker))
+		routes = append(routes,
+			route{path: s.pathPrefix + paths.Health, handler: s.withDetailsAuth(paths.Health, NewHealthHandler(s.healthChecker))},
+			route{path: s.pathPrefix + paths.Live, handler: liveHandler},
+			route{path: s.pathPrefix + paths.Live + "/", handler: liveHandler},
+		)
+	}
+
+	if s.isWithReady {
+		readyPath := s.pathPrefix + paths.Ready
+		if s.isKubeFormat {
+			readyHandler := s.withDetailsAuth(paths.Ready, NewKubeReadyHandler(path.Base(paths.Ready), s.readyTimeout, s.readyChecker))
+			routes = append(routes, route{path: readyPath, handler: readyHandler}, route{path: readyPath + "/", handler: readyHandler})
+		} else {
+			readyHandler := s.withDetailsAuth(paths.Ready, NewReadyHandler(s.readyTimeout, s.readyChecker))
+			routes = append(routes,
+				route{path: readyPath, handler: readyHandler},
+				route{path: readyPath + "/", handler: withCheckFromPath(readyPath+"/", readyHandler)},
+			)
+		}
+	}
+
+	for _, rt := range s.routes {
+		handler := rt.handler
+		if a := s.authenticator(rt.path); a != nil {
+			handler = auth.NewRequireMiddleware(a)(handler)
+		}
+		routes = append(routes, route{path: s.pathPrefix + rt.path, handler: handler})
+	}
+
+	return routes
+}
+
+// Start listens on the port of the host given with WithHost and serves health or/and ready endpoints like Serve
 func (s Server) Start(ctx context.Context, targetPort int) error {
 	if !s.isWithReady && !s.isWithHealth {
 		return errors.New("neither ready nor health checks were started")
 	}
-	router := mux.NewRouter().StrictSlash(false)
 
-	if s.isWithHealth {
-		logging.L.InfoF("Will start health listener with healthz api")
-		router.Handle("/healthz", NewHealthHandler(s.healthChecker))
+	lis, err := net.Listen("tcp", net.JoinHostPort(s.host, strconv.Itoa(targetPort)))
+	if err != nil {
+		return err
 	}
 
-	if s.isWithReady {
-		logging.L.InfoF("Will start ready listener with readyz api")
-		router.Handle("/readyz", NewReadyHandler(s.readyTimeout, s.readyChecker))
+	return s.Serve(ctx, lis)
+}
+
+// Serve serves health or/and ready endpoints on the listener until the context is done, then closes the listener and returns nil,
+// returns an error if neither health nor ready checks were initialized, a path is registered more than once or the server fails
+func (s Server) Serve(ctx context.Context, lis net.Listener) error {
+	if !s.isWithReady && !s.isWithHealth {
+		lis.Close()
+		return errors.New("neither ready nor health checks were started")
+	}
+	err := s.Validate()
+	if err != nil {
+		lis.Close()
+		return err
 	}
 
-	addr := fmt.Sprintf(":%d", targetPort)
+	addr := lis.Addr()
 	httpServer := &http.Server{
-		Addr:    addr,
-		Handler: router,
+		Handler: s.Handler(),
 	}
 
 	logging.L.InfoF("Starting health/ready REST server at %s", addr)
 
-	go func(s *http.Server, c context.Context) {
-		<-c.Done()
+	serveDone := make(chan struct{})
+	defer close(serveDone)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-serveDone:
+			return
+		}
 		logging.L.InfoF("Exiting health REST server at %s", addr)
 
-		err := s.Close()
+		err := httpServer.Close()
 		if err != nil {
 			logging.L.ErrorF(err.Error())
 		} else {
 			logging.L.InfoF("Exit success for health REST %s", addr)
 		}
-	}(httpServer, ctx)
+	}()
 
-	return httpServer.ListenAndServe()
+	s.NotifyStarted(ctx, addr)
+
+	err = httpServer.Serve(lis)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// NotifyStarted sends the address to the channel of WithStarted in the background until the context is done,
+// e.g. for servers which serve Handler on their own listener, nothing is sent without WithStarted
+func (s Server) NotifyStarted(ctx context.Context, addr net.Addr) {
+	if s.started == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case s.started <- addr:
+		case <-ctx.Done():
+		}
+	}()
 }
 
-// NewReadyHandler gives http.Handler implementation for readiness checks
+// NewReadyHandler gives http.Handler implementation for readiness checks, checks are cancelled with the request,
+// the timeout can be lowered with ReadyTimeoutHeader or PrometheusTimeoutHeader, readyTimeout caps it, 0 means no cap.
+// If the checker implements ready.SelectiveChecker, "check" and "exclude" query parameters select tests, "verbose" lists
+// every test like /readyz?verbose of the Kubernetes API server and "format=json" gives the ready.Report,
+// failure reasons and the report are withheld from callers of auth.WithholdDetails requests
 func NewReadyHandler(readyTimeout time.Duration, readyChecker ready.Checker) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		readyCtx, cancelReady := context.WithTimeout(context.Background(), readyTimeout)
+		readyCtx, cancelReady := newReadyContext(r, readyTimeout)
 		defer cancelReady()
 
+		if sc, ok := readyChecker.(ready.SelectiveChecker); ok && isReportRequested(r) {
+			writeReport(w, r, readyCtx, sc)
+			return
+		}
+
 		isReady, err := readyChecker.IsReady(readyCtx)
 		if isReady {
 			w.WriteHeader(http.StatusOK)
@@ -94,7 +351,7 @@ func NewReadyHandler(readyTimeout time.Duration, readyChecker ready.Checker) htt
 		}
 
 		w.WriteHeader(http.StatusInternalServerError)
-		if err == nil {
+		if err == nil || !auth.IsDetailAllowed(r.Context()) {
 			return
 		}
 		_, err = w.Write([]byte(err.Error()))
@@ -104,19 +361,79 @@ func NewReadyHandler(readyTimeout time.Duration, readyChecker ready.Checker) htt
 	})
 }
 
-// NewHealthHandler gives http.Handler implementation for health checks
+// NewHealthHandler gives http.Handler implementation for health checks, if the checker implements health.StatusProvider,
+// the detailed status is given as json for requests with "Accept: application/json" header or "format=json" query parameter,
+// reasons and the json status are withheld from callers of auth.WithholdDetails requests
 func NewHealthHandler(healthChecker health.Checker) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		isHealthy, unhealthyReason := healthChecker.IsHealthy()
+
+		isDetailed := auth.IsDetailAllowed(r.Context())
+		if sp, ok := healthChecker.(health.StatusProvider); ok && isDetailed && isJSONRequested(r) {
+			statusCode := http.StatusOK
+			if !isHealthy {
+				statusCode = http.StatusInternalServerError
+			}
+			writeJSON(w, statusCode, sp.Status())
+			return
+		}
+
 		if isHealthy {
 			w.WriteHeader(http.StatusOK)
 			return
 		}
 
 		w.WriteHeader(http.StatusInternalServerError)
+		if !isDetailed {
+			return
+		}
 		_, err := w.Write([]byte(unhealthyReason))
 		if err != nil {
 			logging.L.ErrorF("Failed to write body: %v", err)
 		}
 	})
 }
+
+// newReadyContext gives the context of the request with the timeout of the request headers capped by maxTimeout,
+// invalid, non-finite and too large header values fall back to maxTimeout
+func newReadyContext(r *http.Request, maxTimeout time.Duration) (context.Context, context.CancelFunc) {
+	timeout := maxTimeout
+	for _, header := range []string{ReadyTimeoutHeader, PrometheusTimeoutHeader} {
+		value := r.Header.Get(header)
+		if value == "" {
+			continue
+		}
+
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds <= 0 || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+			logging.L.DebugF("Ignoring invalid %s header %q", header, value)
+			continue
+		}
+
+		if maxTimeout > 0 && seconds >= maxTimeout.Seconds() {
+			timeout = maxTimeout
+		} else if seconds < maxDurationSeconds {
+			timeout = time.Duration(seconds * float64(time.Second))
+		}
+		break
+	}
+
+	if timeout <= 0 {
+		return context.WithCancel(r.Context())
+	}
+
+	return context.WithTimeout(r.Context(), timeout)
+}
+
+func isJSONRequested(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	err := json.NewEncoder(w).Encode(payload)
+	if err != nil {
+		logging.L.ErrorF("Failed to write body: %v", err)
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/breathbath/healthReadyChecks/auth"
 	"github.com/breathbath/healthReadyChecks/errs"
 	"github.com/breathbath/healthReadyChecks/health"
 	"github.com/breathbath/healthReadyChecks/logging"
@@ -151,6 +154,273 @@ func TestNotEquippedServer(t *testing.T) {
 	assert.EqualError(t, err, "neither ready nor health checks were started")
 }
 
+func TestServerHandler(t *testing.T) {
+	srv := WithHandler(Server{}, "/version", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("1.0.0"))
+	}))
+	srv = WithHealth(srv, healthCheckerMock{isHealthy: true})
+	srv = WithReady(srv, newSelectiveReadyChecker(), time.Second)
+	srv = WithPaths(srv, Paths{Ready: "/ready"})
+	srv = WithPathPrefix(srv, "/internal/")
+
+	router := http.NewServeMux()
+	router.Handle("/internal/", srv.Handler())
+
+	testCases := []struct {
+		target       string
+		expectedCode int
+	}{
+		{target: "/internal/healthz", expectedCode: http.StatusOK},
+		{target: "/internal/livez", expectedCode: http.StatusOK},
+		{target: "/internal/livez/health", expectedCode: http.StatusOK},
+		{target: "/internal/ready", expectedCode: http.StatusInternalServerError},
+		{target: "/internal/ready/db", expectedCode: http.StatusOK},
+		{target: "/internal/ready/kafka", expectedCode: http.StatusInternalServerError},
+		{target: "/internal/version", expectedCode: http.StatusOK},
+		{target: "/internal/readyz", expectedCode: http.StatusNotFound},
+		{target: "/healthz", expectedCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestServerHandlerKubeFormat(t *testing.T) {
+	srv := WithKubeFormat(WithReady(Server{}, newSelectiveReadyChecker(), time.Second))
+	srv = WithPaths(srv, Paths{Ready: "/ready"})
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready?exclude=kafka&verbose", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[+]db ok\n[+]cache ok\n[+]kafka excluded: ok\nready check passed\n", rec.Body.String())
+}
+
+func TestServeLifecycle(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	started := make(chan net.Addr, 1)
+	srv := WithStarted(WithHealth(Server{}, healthCheckerMock{isHealthy: true}), started)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ctx, lis)
+	}()
+
+	addr := <-started
+	assert.Equal(t, lis.Addr(), addr)
+
+	resp, err := callAPI(fmt.Sprintf("http://%s/healthz", addr))
+	assert.NoError(t, err)
+	if err == nil {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	cancel()
+	assert.NoError(t, <-serveErr)
+}
+
+func TestStartOnHostWithRandomPort(t *testing.T) {
+	started := make(chan net.Addr, 1)
+	srv := WithStarted(WithHost(WithHealth(Server{}, healthCheckerMock{isHealthy: true}), "127.0.0.1"), started)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- srv.Start(ctx, 0)
+	}()
+
+	var addr net.Addr
+	select {
+	case addr = <-started:
+	case err := <-startErr:
+		assert.FailNow(t, "server is not started", "%v", err)
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, "127.0.0.1", tcpAddr.IP.String())
+		assert.NotZero(t, tcpAddr.Port)
+	}
+
+	cancel()
+	assert.NoError(t, <-startErr)
+}
+
+func TestServeNotEquippedServer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	err = Server{}.Serve(context.Background(), lis)
+	assert.EqualError(t, err, "neither ready nor health checks were started")
+
+	_, err = lis.Accept()
+	assert.Error(t, err)
+}
+
+func TestServerDuplicatePaths(t *testing.T) {
+	healthServer := WithHealth(Server{}, healthCheckerMock{isHealthy: true})
+
+	testCases := []struct {
+		name          string
+		srv           Server
+		expectedError string
+	}{
+		{
+			name:          "handler at an endpoint path",
+			srv:           WithHandler(healthServer, "/healthz", http.NotFoundHandler()),
+			expectedError: "path /healthz is registered more than once",
+		},
+		{
+			name:          "equal endpoint paths",
+			srv:           WithPaths(healthServer, Paths{Health: "/health", Live: "/health"}),
+			expectedError: "path /health is registered more than once",
+		},
+		{
+			name:          "handler registered twice",
+			srv:           WithHandler(WithHandler(WithPathPrefix(healthServer, "/ops"), "/targets", http.NotFoundHandler()), "/targets", http.NotFoundHandler()),
+			expectedError: "path /ops/targets is registered more than once",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.srv.Validate(), tc.expectedError)
+			assert.Panics(t, func() {
+				tc.srv.Handler()
+			})
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(t, err)
+			if err != nil {
+				return
+			}
+			err = tc.srv.Serve(context.Background(), lis)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+
+	assert.NoError(t, WithHandler(healthServer, "/targets", http.NotFoundHandler()).Validate())
+}
+
+func TestServerAuth(t *testing.T) {
+	srv := WithHandler(Server{}, "/targets", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("db:5432"))
+	}))
+	srv = WithHealth(srv, healthCheckerMock{isHealthy: false, reason: "disk is full"})
+	srv = WithReady(srv, newSelectiveReadyChecker(), time.Second)
+	srv = WithAuth(srv, auth.BearerToken("t1"), "/readyz", "/targets")
+	handler := srv.Handler()
+
+	testCases := []struct {
+		name          string
+		target        string
+		authorization string
+		expectedCode  int
+		expectedBody  string
+	}{
+		{
+			name:          "ready details for allowed caller",
+			target:        "/readyz",
+			authorization: "Bearer t1",
+			expectedCode:  http.StatusInternalServerError,
+			expectedBody:  "Readiness probe failed for kafka: broker is down",
+		},
+		{
+			name:         "ready status for anonymous caller",
+			target:       "/readyz",
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "ready report for anonymous caller",
+			target:       "/readyz?format=json",
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "ready test for anonymous caller",
+			target:       "/readyz/kafka",
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:          "unknown test for allowed caller",
+			target:        "/readyz?check=redis",
+			authorization: "Bearer t1",
+			expectedCode:  http.StatusNotFound,
+			expectedBody:  "unknown ready test redis",
+		},
+		{
+			name:         "unknown test for anonymous caller",
+			target:       "/readyz?check=redis",
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Not Found",
+		},
+		{
+			name:         "unprotected health",
+			target:       "/healthz",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "disk is full",
+		},
+		{
+			name:          "handler for allowed caller",
+			target:        "/targets",
+			authorization: "Bearer t1",
+			expectedCode:  http.StatusOK,
+			expectedBody:  "db:5432",
+		},
+		{
+			name:         "handler for anonymous caller",
+			target:       "/targets",
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "Unauthorized\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestServerAuthOfAllPaths(t *testing.T) {
+	srv := WithKubeFormat(WithHealth(Server{}, healthCheckerMock{isHealthy: false, reason: "disk is full"}))
+	srv = WithAuth(srv, auth.BearerToken("t1"))
+	handler := srv.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez/health", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "internal server error: reason withheld\n", rec.Body.String())
+}
+
 func callAPI(addr string) (*http.Response, error) {
 	req, err := http.NewRequest(
 		http.MethodGet,
@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/breathbath/healthReadyChecks/auth"
+	"github.com/breathbath/healthReadyChecks/logging"
+	"github.com/breathbath/healthReadyChecks/ready"
+)
+
+func selectionFromQuery(r *http.Request) ready.Selection {
+	query := r.URL.Query()
+
+	return ready.Selection{
+		Include: query["check"],
+		Exclude: query["exclude"],
+	}
+}
+
+func isVerbose(r *http.Request) bool {
+	_, ok := r.URL.Query()["verbose"]
+
+	return ok
+}
+
+func isReportRequested(r *http.Request) bool {
+	return !selectionFromQuery(r).IsEmpty() || isVerbose(r) || isJSONRequested(r)
+}
+
+// withCheckFromPath serves /prefix/{name} with the handler as /prefix?check={name}
+func withCheckFromPath(prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, prefix)
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		query := r2.URL.Query()
+		query.Set("check", name)
+		r2.URL.RawQuery = query.Encode()
+
+		next.ServeHTTP(w, r2)
+	})
+}
+
+func writeReport(w http.ResponseWriter, r *http.Request, ctx context.Context, sc ready.SelectiveChecker) {
+	sel := selectionFromQuery(r)
+	report, err := sc.ReportFor(ctx, sel)
+	if errors.Is(err, ready.ErrUnknownTest) {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if !report.IsReady {
+		statusCode = http.StatusInternalServerError
+	}
+
+	isDetailed := auth.IsDetailAllowed(r.Context())
+	switch {
+	case isDetailed && isJSONRequested(r):
+		writeJSON(w, statusCode, report)
+	case isVerbose(r):
+		writeChecks(w, "readyz", true, reportChecks(report), unmatched(sel.Exclude, report.Excluded))
+	case report.IsReady || !isDetailed:
+		w.WriteHeader(statusCode)
+	default:
+		writeText(w, statusCode, reportError(report))
+	}
+}
+
+// reportError formats failures of the report like ready.TestChecker.IsReady
+func reportError(report ready.Report) string {
+	if report.Override != "" {
+		return report.Override
+	}
+	if report.Error != "" {
+		return report.Error
+	}
+
+	failures := make([]string, 0, len(report.Tests))
+	for _, tr := range report.Tests {
+		if !tr.IsReady {
+			failures = append(failures, fmt.Sprintf("Readiness probe failed for %s: %s", tr.Name, tr.Error))
+		}
+	}
+
+	return strings.Join(failures, ", ")
+}
+
+func unmatched(names, matched []string) []string {
+	isMatched := toSet(matched)
+	res := []string{}
+	for _, name := range names {
+		if !isMatched[name] {
+			res = append(res, name)
+		}
+	}
+
+	return res
+}
+
+// writeError writes the error, callers of auth.WithholdDetails requests get only the status text
+func writeError(w http.ResponseWriter, r *http.Request, statusCode int, err error) {
+	text := err.Error()
+	if !auth.IsDetailAllowed(r.Context()) {
+		text = http.StatusText(statusCode)
+	}
+
+	writeText(w, statusCode, text)
+}
+
+func writeText(w http.ResponseWriter, statusCode int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_, err := w.Write([]byte(text))
+	if err != nil {
+		logging.L.ErrorF("Failed to write body: %v", err)
+	}
+}
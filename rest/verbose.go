@@ -0,0 +1,234 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/auth"
+	"github.com/breathbath/healthReadyChecks/health"
+	"github.com/breathbath/healthReadyChecks/logging"
+	"github.com/breathbath/healthReadyChecks/ready"
+)
+
+// checkStatus result of a single named check of the verbose output
+type checkStatus struct {
+	name       string
+	isOK       bool
+	isExcluded bool
+	reason     string
+}
+
+// NewKubeHealthHandler gives http.Handler implementation for health checks in the format of /livez of the Kubernetes API server,
+// every health.Component of a health.ComponentsProvider is a separate check, other checkers are a single "health" check.
+// The endpoint, e.g. "livez", names the result line and the path segment before the name of a single check, e.g. /livez/storage
+func NewKubeHealthHandler(endpoint string, healthChecker health.Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		components := []health.Component{{Name: "health", Checker: healthChecker}}
+		if cp, ok := healthChecker.(health.ComponentsProvider); ok {
+			components = cp.Components()
+		}
+
+		if name := checkNameFromPath(r, endpoint); name != "" {
+			for _, c := range components {
+				if c.Name != name {
+					continue
+				}
+				isHealthy, reason := c.Checker.IsHealthy()
+				writeSingleCheck(w, r, checkStatus{name: name, isOK: isHealthy, reason: reason})
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+
+		exclude := r.URL.Query()["exclude"]
+		isExcluded := toSet(exclude)
+		checks := make([]checkStatus, 0, len(components))
+		names := make([]string, 0, len(components))
+		for _, c := range components {
+			names = append(names, c.Name)
+			if isExcluded[c.Name] {
+				checks = append(checks, checkStatus{name: c.Name, isOK: true, isExcluded: true})
+				continue
+			}
+			isHealthy, reason := c.Checker.IsHealthy()
+			checks = append(checks, checkStatus{name: c.Name, isOK: isHealthy, reason: reason})
+		}
+
+		writeChecks(w, endpoint, isVerbose(r), checks, unmatched(exclude, names))
+	})
+}
+
+// NewKubeReadyHandler gives http.Handler implementation for readiness checks in the format of /readyz of the Kubernetes API server,
+// every test of a ready.SelectiveChecker is a separate check, other checkers are a single "ready" check.
+// The endpoint, e.g. "readyz", names the result line and the path segment before the name of a single check, e.g. /readyz/db,
+// the timeout is handled like in NewReadyHandler
+func NewKubeReadyHandler(endpoint string, readyTimeout time.Duration, readyChecker ready.Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readyCtx, cancelReady := newReadyContext(r, readyTimeout)
+		defer cancelReady()
+
+		name := checkNameFromPath(r, endpoint)
+		exclude := r.URL.Query()["exclude"]
+
+		sc, ok := readyChecker.(ready.SelectiveChecker)
+		if !ok {
+			sc = singleReadyChecker{Checker: readyChecker}
+		}
+
+		sel := ready.Selection{Exclude: exclude}
+		if name != "" {
+			sel = ready.Selection{Include: []string{name}}
+		}
+
+		report, err := sc.ReportFor(readyCtx, sel)
+		if errors.Is(err, ready.ErrUnknownTest) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		checks := reportChecks(report)
+		if name != "" && len(checks) == 1 {
+			writeSingleCheck(w, r, checks[0])
+			return
+		}
+
+		writeChecks(w, endpoint, isVerbose(r), checks, unmatched(exclude, report.Excluded))
+	})
+}
+
+// singleReadyChecker reports a ready.Checker which can't select tests as a single "ready" test
+type singleReadyChecker struct {
+	ready.Checker
+}
+
+const singleReadyTestName = "ready"
+
+// ReportFor ready.SelectiveChecker implementation
+func (src singleReadyChecker) ReportFor(ctx context.Context, sel ready.Selection) (ready.Report, error) {
+	for _, name := range sel.Include {
+		if name != singleReadyTestName {
+			return ready.Report{}, fmt.Errorf("%w %s", ready.ErrUnknownTest, name)
+		}
+	}
+
+	for _, name := range sel.Exclude {
+		if name == singleReadyTestName {
+			return ready.Report{IsReady: true, Tests: []ready.TestReport{}, Excluded: []string{singleReadyTestName}}, nil
+		}
+	}
+
+	isReady, err := src.IsReady(ctx)
+	tr := ready.TestReport{Name: singleReadyTestName, IsReady: isReady}
+	if err != nil {
+		tr.Error = err.Error()
+	}
+
+	return ready.Report{IsReady: isReady, Tests: []ready.TestReport{tr}}, nil
+}
+
+// reportChecks gives a check per test of the report, an override of the report replaces all tests with a single ready.OverrideTestName check
+func reportChecks(report ready.Report) []checkStatus {
+	if report.Override != "" {
+		return []checkStatus{{name: ready.OverrideTestName, isOK: report.IsReady, reason: report.Override}}
+	}
+
+	checks := make([]checkStatus, 0, len(report.Tests)+len(report.Excluded))
+	for _, tr := range report.Tests {
+		checks = append(checks, checkStatus{name: tr.Name, isOK: tr.IsReady, reason: tr.Error})
+	}
+	for _, name := range report.Excluded {
+		checks = append(checks, checkStatus{name: name, isOK: true, isExcluded: true})
+	}
+
+	return checks
+}
+
+// checkNameFromPath gives the path segment after the endpoint, e.g. "db" for /readyz/db, empty if the endpoint is requested
+func checkNameFromPath(r *http.Request, endpoint string) string {
+	prefix := "/" + endpoint + "/"
+	pos := strings.LastIndex(r.URL.Path, prefix)
+	if pos < 0 {
+		return ""
+	}
+
+	return r.URL.Path[pos+len(prefix):]
+}
+
+// writeSingleCheck writes the result of a single check like /livez/{name} of the Kubernetes API server,
+// the reason is withheld from callers of auth.WithholdDetails requests
+func writeSingleCheck(w http.ResponseWriter, r *http.Request, check checkStatus) {
+	if check.isOK {
+		writeText(w, http.StatusOK, "ok")
+		return
+	}
+
+	reason := check.reason
+	if !auth.IsDetailAllowed(r.Context()) {
+		reason = "reason withheld"
+	}
+	writeText(w, http.StatusInternalServerError, fmt.Sprintf("internal server error: %s\n", reason))
+}
+
+// writeChecks writes checks in the format of the Kubernetes API server, e.g. for /readyz?verbose:
+//
+//	[+]db ok
+//	[-]kafka failed: reason withheld
+//	[+]cache excluded: ok
+//	readyz check failed
+//
+// if all checks passed and the output is not verbose, the body is "ok", reasons of failures are logged
+func writeChecks(w http.ResponseWriter, endpoint string, isVerbose bool, checks []checkStatus, unmatchedExcludes []string) {
+	b := &strings.Builder{}
+	failures := make([]string, 0, len(checks))
+	for _, c := range checks {
+		switch {
+		case c.isExcluded:
+			fmt.Fprintf(b, "[+]%s excluded: ok\n", c.name)
+		case c.isOK:
+			fmt.Fprintf(b, "[+]%s ok\n", c.name)
+		default:
+			fmt.Fprintf(b, "[-]%s failed: reason withheld\n", c.name)
+			failures = append(failures, fmt.Sprintf("%s: %s", c.name, c.reason))
+		}
+	}
+	if len(unmatchedExcludes) > 0 {
+		quoted := make([]string, 0, len(unmatchedExcludes))
+		for _, name := range unmatchedExcludes {
+			quoted = append(quoted, fmt.Sprintf("%q", name))
+		}
+		fmt.Fprintf(b, "warn: some health checks cannot be excluded: no matches for %s\n", strings.Join(quoted, ","))
+	}
+
+	if len(failures) > 0 {
+		logging.L.WarnF("%s check failed: %s", endpoint, strings.Join(failures, ", "))
+		fmt.Fprintf(b, "%s check failed\n", endpoint)
+		writeText(w, http.StatusInternalServerError, b.String())
+		return
+	}
+
+	if !isVerbose {
+		writeText(w, http.StatusOK, "ok")
+		return
+	}
+
+	fmt.Fprintf(b, "%s check passed\n", endpoint)
+	writeText(w, http.StatusOK, b.String())
+}
+
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+
+	return set
+}
@@ -0,0 +1,284 @@
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/breathbath/healthReadyChecks/logging"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = time.Second
+)
+
+// Protocol which is used to probe an upstream
+type Protocol string
+
+const (
+	// ProtocolHTTP probes upstream with a GET request, any 2xx status code is a success
+	ProtocolHTTP Protocol = "http"
+	// ProtocolGRPC probes upstream with the health or ready GRPC services of this library
+	ProtocolGRPC Protocol = "grpc"
+)
+
+// Kind tells if a target result contributes to the aggregated health or readiness
+type Kind string
+
+const (
+	// KindHealth target contributes to the aggregated health
+	KindHealth Kind = "health"
+	// KindReady target contributes to the aggregated readiness
+	KindReady Kind = "ready"
+)
+
+// Target upstream endpoint which is continuously probed by the Aggregator
+type Target struct {
+	// Name unique target id used in reasons and details
+	Name string
+	// Protocol of the upstream endpoint
+	Protocol Protocol
+	// Kind of the check
+	Kind Kind
+	// Address is a full url for http targets or host:port for grpc targets
+	Address string
+	// Interval between probes, defaults to 10 seconds
+	Interval time.Duration
+	// Timeout of a single probe, defaults to 1 second
+	Timeout time.Duration
+}
+
+// TargetStatus the last known probe result of a Target
+type TargetStatus struct {
+	Name                string    `json:"name"`
+	Protocol            Protocol  `json:"protocol"`
+	Kind                Kind      `json:"kind"`
+	Address             string    `json:"address"`
+	IsProbed            bool      `json:"probed"`
+	IsOK                bool      `json:"ok"`
+	Error               string    `json:"error,omitempty"`
+	LastProbeAt         time.Time `json:"lastProbeAt"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+}
+
+// Aggregator probes upstream targets in the background and exposes the aggregated result as health.Checker and ready.Checker,
+// so a single sidecar can guard a pod with several processes
+type Aggregator struct {
+	targets   []Target
+	statuses  []TargetStatus
+	subsrFunc func(reason string)
+	lock      sync.RWMutex
+	probeFunc func(ctx context.Context, t Target) error
+}
+
+// NewAggregator constructor for Aggregator, validates targets and applies default intervals and timeouts
+func NewAggregator(targets []Target) (*Aggregator, error) {
+	validTargets := make([]Target, 0, len(targets))
+	statuses := make([]TargetStatus, 0, len(targets))
+	names := make(map[string]bool, len(targets))
+
+	for i, t := range targets {
+		if t.Name == "" {
+			return nil, fmt.Errorf("target %d: empty name", i)
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("target %s: duplicate name", t.Name)
+		}
+		names[t.Name] = true
+
+		if t.Protocol != ProtocolHTTP && t.Protocol != ProtocolGRPC {
+			return nil, fmt.Errorf("target %s: unknown protocol %q", t.Name, t.Protocol)
+		}
+		if t.Kind != KindHealth && t.Kind != KindReady {
+			return nil, fmt.Errorf("target %s: unknown kind %q", t.Name, t.Kind)
+		}
+		if t.Address == "" {
+			return nil, fmt.Errorf("target %s: empty address", t.Name)
+		}
+		if t.Interval <= 0 {
+			t.Interval = defaultInterval
+		}
+		if t.Timeout <= 0 {
+			t.Timeout = defaultTimeout
+		}
+
+		validTargets = append(validTargets, t)
+		statuses = append(statuses, TargetStatus{
+			Name:     t.Name,
+			Protocol: t.Protocol,
+			Kind:     t.Kind,
+			Address:  t.Address,
+		})
+	}
+
+	return &Aggregator{
+		targets:   validTargets,
+		statuses:  statuses,
+		lock:      sync.RWMutex{},
+		probeFunc: probe,
+	}, nil
+}
+
+// Start probes all targets with their intervals until the context is done
+func (a *Aggregator) Start(ctx context.Context) {
+	defer func() {
+		logging.L.DebugF("Exiting sidecar aggregator")
+	}()
+	logging.L.DebugF("Starting sidecar aggregator for %d targets", len(a.targets))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(a.targets))
+	for i := range a.targets {
+		go func(index int) {
+			defer wg.Done()
+			a.probeLoop(ctx, index)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func (a *Aggregator) probeLoop(ctx context.Context, index int) {
+	t := a.targets[index]
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+
+	for {
+		a.probeTarget(ctx, index)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (a *Aggregator) probeTarget(ctx context.Context, index int) {
+	t := a.targets[index]
+	err := a.probeFunc(ctx, t)
+	if ctx.Err() != nil {
+		// probes interrupted by the aggregator shutdown say nothing about the target
+		return
+	}
+	if err != nil {
+		logging.L.WarnF("Sidecar target %s failed: %v", t.Name, err)
+	}
+
+	a.lock.Lock()
+	wasHealthy, _ := a.isHealthy()
+
+	st := &a.statuses[index]
+	st.IsProbed = true
+	st.LastProbeAt = time.Now().UTC()
+	st.IsOK = err == nil
+	st.Error = ""
+	if err != nil {
+		st.Error = err.Error()
+		st.ConsecutiveFailures++
+	} else {
+		st.ConsecutiveFailures = 0
+	}
+
+	isHealthy, reason := a.isHealthy()
+	subsrFunc := a.subsrFunc
+	a.lock.Unlock()
+
+	if wasHealthy && !isHealthy && subsrFunc != nil {
+		subsrFunc(reason)
+	}
+}
+
+// IsHealthy health.Checker implementation, not yet probed targets are considered healthy
+func (a *Aggregator) IsHealthy() (isHealthy bool, unhealthyReason string) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.isHealthy()
+}
+
+func (a *Aggregator) isHealthy() (isHealthy bool, unhealthyReason string) {
+	failures := make([]string, 0)
+	for _, st := range a.statuses {
+		if st.Kind != KindHealth || !st.IsProbed || st.IsOK {
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("Health probe failed for %s: %s", st.Name, st.Error))
+	}
+
+	return len(failures) == 0, strings.Join(failures, ", ")
+}
+
+// SubscribeToUnhealthyChange accepts the callback which will be executed when the aggregated health turns to unhealthy
+func (a *Aggregator) SubscribeToUnhealthyChange(sf func(reason string)) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.subsrFunc = sf
+}
+
+// IsReady ready.Checker implementation, not yet probed targets are considered not ready
+func (a *Aggregator) IsReady(ctx context.Context) (isReady bool, err error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	failures := make([]string, 0)
+	for _, st := range a.statuses {
+		if st.Kind != KindReady {
+			continue
+		}
+		if !st.IsProbed {
+			failures = append(failures, fmt.Sprintf("Readiness probe pending for %s", st.Name))
+			continue
+		}
+		if !st.IsOK {
+			failures = append(failures, fmt.Sprintf("Readiness probe failed for %s: %s", st.Name, st.Error))
+		}
+	}
+
+	if len(failures) == 0 {
+		return true, nil
+	}
+
+	return false, errors.New(strings.Join(failures, ", "))
+}
+
+// Statuses gives the last known results of all targets
+func (a *Aggregator) Statuses() []TargetStatus {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	statuses := make([]TargetStatus, len(a.statuses))
+	copy(statuses, a.statuses)
+
+	return statuses
+}
+
+// NewTargetsHandler gives http.Handler which renders per target details as json,
+// responds with 500 if any of the probed targets has failed
+func NewTargetsHandler(a *Aggregator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := a.Statuses()
+
+		statusCode := http.StatusOK
+		for _, st := range statuses {
+			if st.IsProbed && !st.IsOK {
+				statusCode = http.StatusInternalServerError
+				break
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		err := json.NewEncoder(w).Encode(statuses)
+		if err != nil {
+			logging.L.ErrorF("Failed to write body: %v", err)
+		}
+	})
+}
@@ -0,0 +1,270 @@
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	hrGrpc "github.com/breathbath/healthReadyChecks/grpc"
+	readyProto "github.com/breathbath/healthReadyChecks/protos/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	healthProto "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type healthCheckerMock struct {
+	isHealthy bool
+}
+
+// IsHealthy health.Checker implementation
+func (hcm healthCheckerMock) IsHealthy() (isHealthy bool, isHealthyReason string) {
+	return hcm.isHealthy, ""
+}
+
+// SubscribeToUnhealthyChange health.Checker implementation
+func (hcm healthCheckerMock) SubscribeToUnhealthyChange(sf func(reason string)) {}
+
+type readyCheckerMock struct {
+	isReady bool
+}
+
+// IsReady ready.Checker implementation
+func (rcm readyCheckerMock) IsReady(ctx context.Context) (isReady bool, err error) {
+	return rcm.isReady, nil
+}
+
+func TestNewAggregatorValidation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		targets     []Target
+		expectedErr string
+	}{
+		{
+			name:        "empty name",
+			targets:     []Target{{Protocol: ProtocolHTTP, Kind: KindHealth, Address: "http://localhost"}},
+			expectedErr: "target 0: empty name",
+		},
+		{
+			name: "duplicate name",
+			targets: []Target{
+				{Name: "a", Protocol: ProtocolHTTP, Kind: KindHealth, Address: "http://localhost"},
+				{Name: "a", Protocol: ProtocolHTTP, Kind: KindReady, Address: "http://localhost"},
+			},
+			expectedErr: "target a: duplicate name",
+		},
+		{
+			name:        "unknown protocol",
+			targets:     []Target{{Name: "a", Protocol: "tcp", Kind: KindHealth, Address: "localhost:80"}},
+			expectedErr: `target a: unknown protocol "tcp"`,
+		},
+		{
+			name:        "unknown kind",
+			targets:     []Target{{Name: "a", Protocol: ProtocolHTTP, Kind: "live", Address: "http://localhost"}},
+			expectedErr: `target a: unknown kind "live"`,
+		},
+		{
+			name:        "empty address",
+			targets:     []Target{{Name: "a", Protocol: ProtocolGRPC, Kind: KindReady}},
+			expectedErr: "target a: empty address",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAggregator(tc.targets)
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestAggregatorDefaults(t *testing.T) {
+	a, err := NewAggregator([]Target{{Name: "a", Protocol: ProtocolHTTP, Kind: KindHealth, Address: "http://localhost"}})
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	assert.Equal(t, defaultInterval, a.targets[0].Interval)
+	assert.Equal(t, defaultTimeout, a.targets[0].Timeout)
+}
+
+func TestAggregatorHTTPTargets(t *testing.T) {
+	healthySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthySrv.Close()
+
+	failingSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("db is down"))
+	}))
+	defer failingSrv.Close()
+
+	a, err := NewAggregator([]Target{
+		{Name: "api-health", Protocol: ProtocolHTTP, Kind: KindHealth, Address: healthySrv.URL, Interval: time.Millisecond * 10},
+		{Name: "api-ready", Protocol: ProtocolHTTP, Kind: KindReady, Address: failingSrv.URL, Interval: time.Millisecond * 10},
+	})
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	isReady, err := a.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "Readiness probe pending for api-ready")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	a.Start(ctx)
+
+	isHealthy, reason := a.IsHealthy()
+	assert.True(t, isHealthy)
+	assert.Equal(t, "", reason)
+
+	isReady, err = a.IsReady(context.Background())
+	assert.False(t, isReady)
+	assert.EqualError(t, err, "Readiness probe failed for api-ready: unexpected status code 500: db is down")
+
+	statuses := a.Statuses()
+	assert.Len(t, statuses, 2)
+	assert.True(t, statuses[0].IsOK)
+	assert.True(t, statuses[1].IsProbed)
+	assert.False(t, statuses[1].IsOK)
+	assert.True(t, statuses[1].ConsecutiveFailures > 1)
+}
+
+func TestAggregatorGRPCTargets(t *testing.T) {
+	addr, baseSrv, err := startGRPC(hrGrpc.Server{
+		HealthChecker: healthCheckerMock{isHealthy: false},
+		ReadyChecker:  readyCheckerMock{isReady: true},
+	})
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	defer baseSrv.Stop()
+
+	a, err := NewAggregator([]Target{
+		{Name: "grpc-health", Protocol: ProtocolGRPC, Kind: KindHealth, Address: addr},
+		{Name: "grpc-ready", Protocol: ProtocolGRPC, Kind: KindReady, Address: addr},
+	})
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*300)
+	defer cancel()
+	a.Start(ctx)
+
+	isHealthy, reason := a.IsHealthy()
+	assert.False(t, isHealthy)
+	assert.Contains(t, reason, "Health probe failed for grpc-health: GRPC Health client received an unhealthy status")
+
+	isReady, err := a.IsReady(context.Background())
+	assert.True(t, isReady)
+	assert.NoError(t, err)
+}
+
+func TestGRPCProbeCancellation(t *testing.T) {
+	// the listener accepts connections but never speaks GRPC, so only the context stops the probe
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	defer lis.Close()
+
+	for _, kind := range []Kind{KindHealth, KindReady} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		start := time.Now()
+		err = probe(ctx, Target{Name: "silent", Protocol: ProtocolGRPC, Kind: kind, Address: lis.Addr().String(), Timeout: time.Second * 10})
+		cancel()
+
+		assert.Error(t, err)
+		assert.Less(t, int64(time.Since(start)), int64(time.Second*2), "probe of %s should stop with the context", kind)
+	}
+}
+
+func TestAggregatorSubscription(t *testing.T) {
+	a, err := NewAggregator([]Target{
+		{Name: "db", Protocol: ProtocolHTTP, Kind: KindHealth, Address: "http://localhost", Interval: time.Millisecond * 10},
+	})
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	probeErrs := []error{nil, errors.New("conn refused"), errors.New("conn refused"), nil}
+	probesCount := 0
+	a.probeFunc = func(ctx context.Context, t Target) error {
+		if probesCount >= len(probeErrs) {
+			return nil
+		}
+		err := probeErrs[probesCount]
+		probesCount++
+		return err
+	}
+
+	reasons := make([]string, 0)
+	a.SubscribeToUnhealthyChange(func(reason string) {
+		reasons = append(reasons, reason)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	a.Start(ctx)
+
+	assert.Equal(t, []string{"Health probe failed for db: conn refused"}, reasons)
+}
+
+func TestTargetsHandler(t *testing.T) {
+	a, err := NewAggregator([]Target{
+		{Name: "db", Protocol: ProtocolHTTP, Kind: KindReady, Address: "http://localhost"},
+	})
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	a.probeFunc = func(ctx context.Context, t Target) error {
+		return errors.New("conn refused")
+	}
+
+	a.probeTarget(context.Background(), 0)
+
+	rec := httptest.NewRecorder()
+	NewTargetsHandler(a).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/targets", http.NoBody))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	statuses := []TargetStatus{}
+	err = json.Unmarshal(rec.Body.Bytes(), &statuses)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, "db", statuses[0].Name)
+	assert.Equal(t, "conn refused", statuses[0].Error)
+}
+
+func startGRPC(srv hrGrpc.Server) (addr string, baseSrv *grpc.Server, err error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+
+	baseSrv = grpc.NewServer()
+	readyProto.RegisterReadyServer(baseSrv, srv)
+	healthProto.RegisterHealthServer(baseSrv, srv)
+
+	go func() {
+		_ = baseSrv.Serve(lis)
+	}()
+
+	return lis.Addr().String(), baseSrv, nil
+}
@@ -0,0 +1,24 @@
+package sidecar
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/breathbath/healthReadyChecks/grpc"
+	"github.com/breathbath/healthReadyChecks/ready"
+)
+
+// probe runs a single check against the target and returns nil if the upstream reported success
+func probe(ctx context.Context, t Target) error {
+	switch t.Protocol {
+	case ProtocolHTTP:
+		return ready.CheckHTTP(ctx, t.Address, t.Timeout)
+	case ProtocolGRPC:
+		if t.Kind == KindReady {
+			return grpc.CheckReadyContext(ctx, t.Address, t.Name, t.Timeout)
+		}
+		return grpc.CheckHealthContext(ctx, t.Address, t.Name, t.Timeout)
+	default:
+		return fmt.Errorf("unknown protocol %q of target %s", t.Protocol, t.Name)
+	}
+}